@@ -0,0 +1,115 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrActorStopped is returned by Actor.Send if the actor doesn't accept
+// messages anymore
+var ErrActorStopped = errors.New("actor stopped")
+
+// Actor handles messages sent to its mailbox one by one. Like Pool, Actor is a
+// task itself, run by its Run method, so an error returned by the handler
+// fails the group running it.
+//
+// Every message accepted by Send is either handled or, if the actor stops
+// before getting to it, reported by Undelivered.
+type Actor[M any] struct {
+	handler func(ctx context.Context, msg M) error
+	mailbox chan M
+	done    chan struct{}
+
+	mu          sync.RWMutex
+	closed      bool
+	undelivered []M
+}
+
+// NewActor creates an actor with a mailbox of the given capacity, handling
+// messages with handler
+func NewActor[M any](mailboxSize int, handler func(ctx context.Context, msg M) error) *Actor[M] {
+	return &Actor[M]{
+		handler: handler,
+		mailbox: make(chan M, mailboxSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Send puts the message into the mailbox, blocking while it's full. If ctx
+// closes first, ctx.Err() is returned. ErrActorStopped is returned if the actor
+// has been closed or has finished running.
+func (a *Actor[M]) Send(ctx context.Context, msg M) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return ErrActorStopped
+	}
+	select {
+	case a.mailbox <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-a.done:
+		return ErrActorStopped
+	}
+}
+
+// Close stops accepting new messages. Messages sent so far are still handled,
+// then Run returns.
+func (a *Actor[M]) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.closed {
+		a.closed = true
+		close(a.mailbox)
+	}
+}
+
+// Run handles messages until the actor is closed and its mailbox drained, ctx
+// closes or the handler fails. When Run returns, the actor is closed and the
+// messages left in the mailbox are moved to Undelivered. Run must be called
+// once.
+func (a *Actor[M]) Run(ctx context.Context) error {
+	defer a.reject()
+	defer close(a.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-a.mailbox:
+			if !ok {
+				return nil
+			}
+			if err := a.handler(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Undelivered returns the messages accepted by Send which haven't been handled
+// because the actor has stopped
+func (a *Actor[M]) Undelivered() []M {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return append([]M(nil), a.undelivered...)
+}
+
+// reject closes the actor and moves the messages left in the mailbox to
+// undelivered
+func (a *Actor[M]) reject() {
+	a.Close()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for msg := range a.mailbox {
+		a.undelivered = append(a.undelivered, msg)
+	}
+}
@@ -0,0 +1,49 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActor(t *testing.T) {
+	ctx := context.Background()
+
+	var sum int
+	a := NewActor(2, func(ctx context.Context, msg int) error {
+		sum += msg
+		return nil
+	})
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("actor", Exit, a.Run)
+		for i := 1; i <= 10; i++ {
+			if err := a.Send(ctx, i); err != nil {
+				return err
+			}
+		}
+		a.Close()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 55, sum)
+	require.Empty(t, a.Undelivered())
+	require.ErrorIs(t, a.Send(ctx, 1), ErrActorStopped)
+}
+
+func TestActorUndelivered(t *testing.T) {
+	ctx := context.Background()
+
+	a := NewActor(3, func(ctx context.Context, msg int) error {
+		return errors.New("oops")
+	})
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, a.Send(ctx, i))
+	}
+
+	require.EqualError(t, a.Run(ctx), "oops")
+	require.Equal(t, []int{2, 3}, a.Undelivered())
+	require.ErrorIs(t, a.Send(ctx, 4), ErrActorStopped)
+}
@@ -0,0 +1,70 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Attach registers a goroutine started outside of the group, e.g. by a
+// third-party library, as a subtask of the group. The goroutine must call the
+// returned function once it finishes, passing the result the same way as a
+// task returns it. Subsequent calls are ignored.
+//
+// The attached subtask is treated as any other one by Wait and OnExit
+// handling, except that Restart acts like Continue because the group can't
+// start the goroutine again. The goroutine is expected to watch the context of
+// the group to exit on shutdown. The attached subtask doesn't occupy a slot of
+// WithMaxConcurrent.
+//
+// If the group has been closed, Attach panics with ErrGroupClosed.
+func (g *Group) Attach(name string, onExit OnExit) (done func(error)) {
+	key, id := g.newTaskID()
+
+	log := g.log.Named(name)
+	st := &subtask{
+		group:    g,
+		key:      key,
+		id:       id,
+		name:     name,
+		path:     g.taskPath(name),
+		onExit:   onExit,
+		attached: true,
+	}
+	st.ctx = withLogger(context.WithValue(context.WithValue(g.ctx, taskIDKey, id), subtaskKey, st), log)
+
+	started := g.clock.Now()
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		panic(errors.WithMessagef(ErrGroupClosed, "attaching task %s", st.path))
+	}
+	g.running++
+	g.counts.spawned++
+	g.tasks[st.id] = st
+	st.state = TaskRunning
+	st.started = started
+	g.mu.Unlock()
+
+	g.metrics.taskSpawned(st.path)
+	g.metrics.taskStarted(st.path)
+	g.observe(Event{Type: EventTaskSpawned, Task: st.path, ID: st.id})
+	g.observe(Event{Type: EventTaskStarted, Task: st.path, ID: st.id})
+	log.Debug("Task attached", "id", hexID(id), "onExit", onExit)
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			duration := g.clock.Now().Sub(started)
+			g.metrics.taskFinished(st.path, duration, err)
+			g.observe(Event{Type: EventTaskFinished, Task: st.path, ID: st.id, Err: err})
+			log.Debug("Task finished", "error", err, "duration", duration)
+
+			g.mu.Lock()
+			st.duration = duration
+			g.mu.Unlock()
+			g.finishTask(st, err)
+		})
+	}
+}
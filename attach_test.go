@@ -0,0 +1,55 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttach(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	done := g.Attach("external", Continue)
+	require.Equal(t, 1, g.Running())
+	select {
+	case <-g.Done():
+		t.Fatal("group should wait for the attached goroutine")
+	default:
+	}
+
+	go func() {
+		done(nil)
+		done(errors.New("ignored"))
+	}()
+	require.NoError(t, g.Wait())
+	require.NoError(t, g.Context().Err())
+}
+
+func TestAttachError(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	done := g.Attach("external", Continue)
+	go done(errors.New("oops"))
+	require.EqualError(t, g.Wait(), "oops")
+}
+
+func TestAttachExit(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(1))
+
+	done := g.Attach("external", Exit)
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		done(nil)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, g.Wait())
+}
@@ -0,0 +1,76 @@
+package parallel
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Backoff configures exponentially growing delays between repeated runs of a
+// task
+type Backoff struct {
+	// Initial is the delay before the first repetition
+	Initial time.Duration
+
+	// Max is the upper bound of the delay
+	Max time.Duration
+
+	// Multiplier is the factor the delay grows by after each repetition
+	Multiplier float64
+
+	// Jitter is the fraction of the delay, between 0 and 1, by which the delay
+	// is randomly decreased, so repeated runs of many tasks are spread in time
+	Jitter float64
+}
+
+// DefaultBackoff is the backoff used unless configured otherwise
+var DefaultBackoff = Backoff{
+	Initial:    100 * time.Millisecond,
+	Max:        30 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// Delay returns the delay before the repetition number attempt, counting
+// from 0
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := float64(b.Initial)
+	for i := 0; i < attempt && delay < float64(b.Max); i++ {
+		delay *= b.Multiplier
+	}
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		delay -= delay * b.Jitter * rand.Float64() //nolint:gosec // jitter does not need cryptographic randomness
+	}
+	return time.Duration(delay)
+}
+
+// Permanent marks err as not worth retrying. A task running in Restart mode
+// which returns such an error is not restarted, and the error is handled as
+// in any other mode.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked by Permanent
+func IsPermanent(err error) bool {
+	return errors.As(err, new(permanentError))
+}
+
+type permanentError struct {
+	err error
+}
+
+func (err permanentError) Error() string {
+	return err.err.Error()
+}
+
+func (err permanentError) Unwrap() error {
+	return err.err
+}
@@ -0,0 +1,38 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	require.Equal(t, time.Second, b.Delay(0))
+	require.Equal(t, 2*time.Second, b.Delay(1))
+	require.Equal(t, 4*time.Second, b.Delay(2))
+	require.Equal(t, 5*time.Second, b.Delay(3))
+	require.Equal(t, 5*time.Second, b.Delay(100))
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: time.Second, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := b.Delay(i)
+		require.LessOrEqual(t, d, time.Second)
+		require.GreaterOrEqual(t, d, 500*time.Millisecond)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	require.NoError(t, Permanent(nil))
+
+	err := errors.New("oops")
+	require.False(t, IsPermanent(err))
+	require.True(t, IsPermanent(Permanent(err)))
+	require.True(t, IsPermanent(errors.Wrap(Permanent(err), "wrapped")))
+	require.ErrorIs(t, Permanent(err), err)
+	require.EqualError(t, Permanent(err), "oops")
+}
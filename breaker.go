@@ -0,0 +1,51 @@
+package parallel
+
+import "time"
+
+// CircuitBreaker suspends restarts of a subtask in Restart mode failing too
+// often, see WithCircuitBreaker
+type CircuitBreaker struct {
+	// Failures is the number of failures opening the breaker
+	Failures int
+
+	// Window is the period the failures are counted in, zero means the
+	// failures are counted until the breaker opens
+	Window time.Duration
+
+	// Cooldown is the delay before the subtask is restarted once the breaker
+	// opens
+	Cooldown time.Duration
+}
+
+// WithCircuitBreaker makes the group suspend restarts of a subtask in Restart
+// mode for the cooldown period after it fails the configured number of times
+// within the window, instead of applying the backoff. Meanwhile the subtask
+// is reported as TaskCoolingDown. After the cooldown the failures and the
+// backoff start from scratch.
+func WithCircuitBreaker(b CircuitBreaker) GroupOption {
+	return func(g *Group) {
+		g.breaker = b
+	}
+}
+
+// trip records the failure at the given time in recent failures and tells if
+// the breaker opens
+func (b CircuitBreaker) trip(recent *[]time.Time, now time.Time) bool {
+	if b.Failures <= 0 {
+		return false
+	}
+
+	kept := (*recent)[:0]
+	for _, t := range *recent {
+		if b.Window <= 0 || now.Sub(t) < b.Window {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	if len(kept) >= b.Failures {
+		*recent = kept[:0]
+		return true
+	}
+	*recent = kept
+	return false
+}
@@ -0,0 +1,44 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx,
+		WithRestartBackoff(Backoff{Initial: time.Millisecond, Max: time.Millisecond}),
+		WithCircuitBreaker(CircuitBreaker{Failures: 3, Window: time.Hour, Cooldown: time.Hour}))
+
+	var runs atomic.Int32
+	g.Spawn("flaky", Restart, func(ctx context.Context) error {
+		runs.Add(1)
+		return errors.New("oops")
+	})
+
+	require.Eventually(t, func() bool {
+		return g.Tasks()[0].State == TaskCoolingDown
+	}, time.Second, time.Millisecond)
+	require.EqualValues(t, 3, runs.Load())
+	require.Equal(t, "cooling down", TaskCoolingDown.String())
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	b := CircuitBreaker{Failures: 2, Window: time.Minute}
+	var recent []time.Time
+	now := time.Unix(0, 0)
+
+	require.False(t, b.trip(&recent, now))
+	require.False(t, b.trip(&recent, now.Add(2*time.Minute)))
+	require.True(t, b.trip(&recent, now.Add(150*time.Second)))
+	require.Empty(t, recent)
+}
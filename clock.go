@@ -0,0 +1,151 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the time-based features of this package:
+// timeouts, restart backoff, periodic and delayed tasks, retries, watchdogs
+// and liveness checks. Tests may use a fake clock to run those features
+// instantly and deterministically, see WithClock.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// NewTimer creates a timer sending the current time on its channel after
+	// the duration
+	NewTimer(d time.Duration) Timer
+
+	// AfterFunc creates a timer calling f in its own goroutine after the
+	// duration. The channel of the timer is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by Clock, see time.Timer
+type Timer interface {
+	// C returns the channel the time is sent on when the timer expires
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing, see time.Timer.Stop
+	Stop() bool
+
+	// Reset changes the timer to expire after the duration, see
+	// time.Timer.Reset
+	Reset(d time.Duration) bool
+}
+
+// RealClock is the Clock backed by the time package, used by default
+var RealClock Clock = realClock{}
+
+// WithClock makes the group and its subgroups use the clock. The clock is
+// also passed to the subtasks in their context, see ContextWithClock.
+func WithClock(clock Clock) GroupOption {
+	return func(g *Group) {
+		g.clock = clock
+	}
+}
+
+// ContextWithClock returns a context making the functions of this package
+// taking it, like Retry, Periodic or RunWithTimeout, use the clock
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey, clock)
+}
+
+// clockFromContext returns the clock stored in ctx, or RealClock
+func clockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey).(Clock); ok {
+		return clock
+	}
+	return RealClock
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{Timer: time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{Timer: time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// withTimeout is context.WithTimeout measuring the timeout by the clock
+func withTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	c := &clockContext{
+		Context:  ctx,
+		deadline: clock.Now().Add(timeout),
+		done:     make(chan struct{}),
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(c.deadline) {
+		c.deadline = deadline
+	}
+	c.mu.Lock()
+	c.timer = clock.AfterFunc(timeout, func() {
+		c.cancel(context.DeadlineExceeded)
+	})
+	c.stop = context.AfterFunc(ctx, func() {
+		c.cancel(ctx.Err())
+	})
+	c.mu.Unlock()
+	return c, func() {
+		c.cancel(context.Canceled)
+	}
+}
+
+// clockContext is a context with a deadline measured by a Clock
+type clockContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	mu    sync.Mutex
+	timer Timer
+	stop  func() bool
+	err   error
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *clockContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+	c.timer.Stop()
+	c.stop()
+}
@@ -0,0 +1,85 @@
+package parallel
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Command returns a task running the external command. When the context of
+// the task closes, the process is sent SIGTERM, and killed if it doesn't exit
+// within the grace period. The name is used in error messages.
+//
+// If the standard output or error of cmd is not set, the one of the subtask
+// configured by WithOutput or WithPrefixedOutput is used, if any.
+//
+// The task returns ctx.Err() if the process has been terminated because ctx
+// closed, or an error describing the exit status if the process has failed.
+//
+// The command can only be run once, so the task fails with a permanent error
+// if it's run again, e.g. in Restart mode or with WithRetry. Use CommandFunc
+// for tasks which may be run again.
+func Command(name string, cmd *exec.Cmd, grace time.Duration) Task {
+	return func(ctx context.Context) error {
+		if cmd.Process != nil {
+			return Permanent(errors.Errorf("command %s has already been run, use CommandFunc to run it again", name))
+		}
+		return runCommand(ctx, name, cmd, grace)
+	}
+}
+
+// CommandFunc returns a task running the external command the same way as
+// Command does, except that the command is created by newCmd on every run, so
+// the task may be run again, e.g. in Restart mode.
+func CommandFunc(name string, newCmd func() *exec.Cmd, grace time.Duration) Task {
+	return func(ctx context.Context) error {
+		return runCommand(ctx, name, newCmd(), grace)
+	}
+}
+
+func runCommand(ctx context.Context, name string, cmd *exec.Cmd, grace time.Duration) error {
+	if out, ok := ctx.Value(outputKey).(*output); ok {
+		if cmd.Stdout == nil {
+			cmd.Stdout = out.stdout
+		}
+		if cmd.Stderr == nil {
+			cmd.Stderr = out.stderr
+		}
+		// Don't wait forever for the output of orphaned children
+		if cmd.WaitDelay == 0 {
+			cmd.WaitDelay = grace
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrapf(err, "starting command %s", name)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return errors.Wrapf(err, "command %s failed", name)
+	case <-ctx.Done():
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		_ = cmd.Process.Kill()
+	}
+	timer := clockFromContext(ctx).NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-exited:
+	case <-timer.C():
+		loggerFromContext(ctx).Error("Command hasn't exited in time, killing it", "command", name, "grace", grace)
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,49 @@
+package parallel
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	ctx := context.Background()
+
+	require.NoError(t, Command("true", exec.Command("true"), time.Second)(ctx))
+	require.EqualError(t, Command("false", exec.Command("false"), time.Second)(ctx), "command false failed: exit status 1")
+}
+
+func TestCommandRunAgain(t *testing.T) {
+	ctx := context.Background()
+
+	task := Command("true", exec.Command("true"), time.Second)
+	require.NoError(t, task(ctx))
+	err := task(ctx)
+	require.True(t, IsPermanent(err))
+	require.EqualError(t, err, "command true has already been run, use CommandFunc to run it again")
+
+	var runs int
+	task = CommandFunc("true", func() *exec.Cmd {
+		runs++
+		return exec.Command("true")
+	}, time.Second)
+	require.NoError(t, task(ctx))
+	require.NoError(t, task(ctx))
+	require.Equal(t, 2, runs)
+}
+
+func TestCommandTerminated(t *testing.T) {
+	ctx := context.Background()
+
+	for _, script := range []string{"sleep 60", "trap '' TERM; sleep 60"} {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		started := time.Now()
+		err := Command("sleep", exec.Command("sh", "-c", script), 100*time.Millisecond)(ctx)
+		cancel()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(started), 10*time.Second)
+	}
+}
@@ -0,0 +1,116 @@
+// Package compat provides a drop-in replacement for errgroup.Group from
+// golang.org/x/sync/errgroup built on top of parallel.Group, so codebases
+// can switch incrementally while gaining panic recovery and logging.
+package compat
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/parallel"
+)
+
+// Group has the same API and semantics as errgroup.Group, except that a
+// panic in a goroutine is returned by Wait as parallel.PanicError.
+//
+// The zero Group is valid, uses context.Background and does not cancel on
+// error. Like any parallel.Group, it logs to slog.Default unless another
+// logger is set by parallel.SetDefaultLogger.
+type Group struct {
+	once  sync.Once
+	ctx   context.Context
+	group *parallel.Group
+	sem   chan struct{}
+}
+
+// WithContext returns a new Group and an associated context derived from ctx.
+//
+// The derived context is canceled the first time a function passed to Go
+// returns a non-nil error or the first time Wait returns, whichever occurs
+// first.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	g := &Group{ctx: ctx}
+	g.init()
+	return g, g.group.Context()
+}
+
+func (g *Group) init() {
+	g.once.Do(func() {
+		if g.ctx == nil {
+			g.ctx = context.Background()
+		}
+		g.group = parallel.NewGroup(g.ctx)
+	})
+}
+
+// Go calls the given function in a new goroutine. It blocks until the new
+// goroutine can be added without the number of active goroutines in the group
+// exceeding the configured limit.
+//
+// The first call to return a non-nil error cancels the group's context, if the
+// group was created by calling WithContext. The error will be returned by
+// Wait.
+func (g *Group) Go(fn func() error) {
+	g.init()
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.spawn(fn)
+}
+
+// TryGo calls the given function in a new goroutine only if the number of
+// active goroutines in the group is currently below the configured limit.
+//
+// The return value reports whether the goroutine was started.
+func (g *Group) TryGo(fn func() error) bool {
+	g.init()
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.spawn(fn)
+	return true
+}
+
+// SetLimit limits the number of active goroutines in this group to at most n.
+// A negative value indicates no limit.
+//
+// Any subsequent call to the Go method will block until it can add an active
+// goroutine without exceeding the configured limit.
+//
+// The limit must not be modified while any goroutines in the group are active.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(errors.Errorf("compat: modify limit while %v goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Wait blocks until all function calls from the Go method have returned, then
+// returns the first non-nil error (if any) from them.
+func (g *Group) Wait() error {
+	g.init()
+	err := g.group.Wait()
+	g.group.Exit(nil)
+	return err
+}
+
+func (g *Group) spawn(fn func() error) {
+	g.group.Go(func() error {
+		if g.sem != nil {
+			defer func() {
+				<-g.sem
+			}()
+		}
+		return fn()
+	})
+}
@@ -0,0 +1,89 @@
+package compat
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/parallel"
+)
+
+func TestWithContext(t *testing.T) {
+	ctx := context.Background()
+	g, ctx := WithContext(ctx)
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Go(func() error {
+		return errors.New("oops")
+	})
+	require.EqualError(t, g.Wait(), "oops")
+	require.Error(t, ctx.Err())
+}
+
+func TestWaitCancelsContext(t *testing.T) {
+	ctx := context.Background()
+	g, ctx := WithContext(ctx)
+
+	var count atomic.Int32
+	for i := 0; i < 3; i++ {
+		g.Go(func() error {
+			count.Add(1)
+			return nil
+		})
+	}
+	require.NoError(t, g.Wait())
+	require.EqualValues(t, 3, count.Load())
+	require.Error(t, ctx.Err())
+}
+
+func TestPanic(t *testing.T) {
+	ctx := context.Background()
+	g, _ := WithContext(ctx)
+
+	g.Go(func() error {
+		panic("oops")
+	})
+	require.IsType(t, parallel.PanicError{}, g.Wait())
+}
+
+func TestLimit(t *testing.T) {
+	ctx := context.Background()
+	g, _ := WithContext(ctx)
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+	require.False(t, g.TryGo(func() error {
+		return nil
+	}))
+	close(release)
+	require.NoError(t, g.Wait())
+	require.True(t, g.TryGo(func() error {
+		return nil
+	}))
+	require.NoError(t, g.Wait())
+}
+
+func TestZeroGroup(t *testing.T) {
+	var g Group
+	g.Go(func() error {
+		return errors.New("oops")
+	})
+	require.EqualError(t, g.Wait(), "oops")
+
+	g2, ctx := WithContext(context.Background())
+	g2.Go(func() error {
+		return nil
+	})
+	require.NoError(t, g2.Wait())
+	require.Error(t, ctx.Err())
+}
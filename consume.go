@@ -0,0 +1,93 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Consume receives items from ch using the given number of workers, each
+// calling handler for one item at a time, until ch is closed and drained.
+//
+// The first error returned by handler stops the other workers and is
+// returned. If ctx closes, the workers stop without draining ch and ctx.Err()
+// is returned.
+func Consume[T any](ctx context.Context, ch <-chan T, workers int, handler func(ctx context.Context, item T) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		for i := 0; i < workers; i++ {
+			spawn(fmt.Sprintf("worker-%d", i), Continue, func(ctx context.Context) error {
+				for {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case item, ok := <-ch:
+						if !ok {
+							return nil
+						}
+						if err := handler(ctx, item); err != nil {
+							return err
+						}
+					}
+				}
+			})
+		}
+		return nil
+	})
+}
+
+// ConsumeBatch receives items from ch and passes them to handler in batches of
+// at most maxBatch items. A batch is also passed once maxWait has elapsed since
+// its first item was received. The handler gets a new slice every time.
+//
+// When ch is closed, the remaining items are flushed and the result of handler
+// is returned. When ctx closes, the remaining items are flushed with a context
+// which isn't closed, and ctx.Err() is returned unless handler fails.
+func ConsumeBatch[T any](ctx context.Context, ch <-chan T, maxBatch int, maxWait time.Duration, handler func(ctx context.Context, batch []T) error) error {
+	clock := clockFromContext(ctx)
+
+	var batch []T
+	var timer Timer
+	var expired <-chan time.Time
+	flush := func(ctx context.Context) error {
+		if timer != nil {
+			timer.Stop()
+			timer, expired = nil, nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		b := batch
+		batch = nil
+		return handler(ctx, b)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := flush(context.WithoutCancel(ctx)); err != nil {
+				return err
+			}
+			return ctx.Err()
+		case item, ok := <-ch:
+			if !ok {
+				return flush(ctx)
+			}
+			if batch == nil {
+				batch = make([]T, 0, maxBatch)
+				timer = clock.NewTimer(maxWait)
+				expired = timer.C()
+			}
+			batch = append(batch, item)
+			if len(batch) < maxBatch {
+				continue
+			}
+		case <-expired:
+		}
+		if err := flush(ctx); err != nil {
+			return err
+		}
+	}
+}
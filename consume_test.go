@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsume(t *testing.T) {
+	ctx := context.Background()
+
+	ch := make(chan int, 100)
+	for i := 1; i <= 100; i++ {
+		ch <- i
+	}
+	close(ch)
+
+	var sum atomic.Int64
+	require.NoError(t, Consume(ctx, ch, 4, func(ctx context.Context, item int) error {
+		sum.Add(int64(item))
+		return nil
+	}))
+	require.EqualValues(t, 5050, sum.Load())
+}
+
+func TestConsumeError(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	err := Consume(ctx, ch, 4, func(ctx context.Context, item int) error {
+		if item == 10 {
+			return errors.New("oops")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "oops")
+}
+
+func TestConsumeBatch(t *testing.T) {
+	ctx := context.Background()
+
+	ch := make(chan int)
+	batches := make(chan []int, 10)
+	result := make(chan error, 1)
+	go func() {
+		result <- ConsumeBatch(ctx, ch, 3, 20*time.Millisecond, func(ctx context.Context, batch []int) error {
+			batches <- batch
+			return nil
+		})
+	}()
+
+	for i := 1; i <= 4; i++ {
+		ch <- i
+	}
+	require.Equal(t, []int{1, 2, 3}, <-batches)
+	// The partial batch is flushed after maxWait
+	require.Equal(t, []int{4}, <-batches)
+
+	ch <- 5
+	close(ch)
+	require.Equal(t, []int{5}, <-batches)
+	require.NoError(t, <-result)
+}
+
+func TestConsumeBatchCanceled(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	ch := make(chan int)
+	var flushed []int
+	result := make(chan error, 1)
+	go func() {
+		result <- ConsumeBatch(ctx, ch, 10, time.Hour, func(ctx context.Context, batch []int) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			flushed = batch
+			return nil
+		})
+	}()
+
+	ch <- 1
+	ch <- 2
+	cancel()
+	require.ErrorIs(t, <-result, context.Canceled)
+	require.Equal(t, []int{1, 2}, flushed)
+}
@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FatalFunc is called with the hierarchical name of a subtask in Crash mode,
+// see TaskInfo.Path, and its result, when the subtask exits. It is expected to
+// terminate the process.
+type FatalFunc func(name string, err error)
+
+var (
+	fatalHandlerMu sync.RWMutex
+	fatalHandler   FatalFunc
+)
+
+// SetFatalHandler sets the function called when a subtask in Crash mode
+// exits. By default the exit is logged and the process exits with status 1.
+// Passing nil restores the default. If the handler returns, the subtask is
+// handled as in Fail mode.
+func SetFatalHandler(fn FatalFunc) {
+	fatalHandlerMu.Lock()
+	defer fatalHandlerMu.Unlock()
+
+	fatalHandler = fn
+}
+
+// crash terminates the process because the subtask in Crash mode has exited,
+// unless it has exited because of the shutdown of the group or it has been
+// canceled by its handle
+func (g *Group) crash(st *subtask, err error) {
+	if st.handle.canceledIndividually(err) {
+		return
+	}
+
+	g.mu.Lock()
+	closing := g.closing
+	g.mu.Unlock()
+
+	if closing && (err == nil || errors.Is(err, context.Canceled)) {
+		return
+	}
+
+	fatalHandlerMu.RLock()
+	fn := fatalHandler
+	fatalHandlerMu.RUnlock()
+
+	if fn == nil {
+		g.log.Error("Task crashed", "task", st.path, "error", err)
+		os.Exit(1)
+	}
+	fn(st.path, err)
+}
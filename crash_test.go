@@ -0,0 +1,36 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrash(t *testing.T) {
+	ctx := context.Background()
+
+	var crashed []string
+	SetFatalHandler(func(name string, err error) {
+		crashed = append(crashed, name+": "+err.Error())
+	})
+	defer SetFatalHandler(nil)
+
+	g := NewGroup(ctx)
+	g.Spawn("fatal", Crash, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.EqualError(t, g.Wait(), "oops")
+	require.Equal(t, []string{"fatal: oops"}, crashed)
+
+	crashed = nil
+	g = NewGroup(ctx)
+	g.Spawn("fatal", Crash, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.Empty(t, crashed)
+}
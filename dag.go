@@ -0,0 +1,152 @@
+package parallel
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// DAG is a set of tasks with dependencies between them. Each task is started
+// only after all its dependencies complete successfully. If a task fails, the
+// tasks depending on it, directly or transitively, are never started, and the
+// execution shuts down.
+//
+// Example:
+//
+//	dag := parallel.NewDAG()
+//	dag.Add("fetch", fetch)
+//	dag.Add("compile", compile, "fetch")
+//	dag.Add("lint", lint, "fetch")
+//	dag.Add("package", pack, "compile", "lint")
+//	err := dag.Run(ctx)
+type DAG struct {
+	nodes []*dagNode
+	index map[string]*dagNode
+}
+
+type dagNode struct {
+	name string
+	task Task
+	deps []string
+
+	// Set during execution
+	waiting    int
+	dependents []*dagNode
+}
+
+// NewDAG creates a new empty DAG
+func NewDAG() *DAG {
+	return &DAG{index: map[string]*dagNode{}}
+}
+
+// Add adds a task depending on the tasks with the given names. Dependencies
+// may be added later than the task depending on them, but all of them must be
+// added before Run.
+func (d *DAG) Add(name string, task Task, deps ...string) {
+	n := &dagNode{name: name, task: task, deps: deps}
+	d.nodes = append(d.nodes, n)
+	d.index[name] = n
+}
+
+// Run runs the tasks in the order defined by dependencies, running
+// independent tasks in parallel. Run returns when all the tasks complete, any
+// of them fails, or ctx closes.
+//
+// An error is returned without running any task if a dependency is missing or
+// the dependencies form a cycle.
+func (d *DAG) Run(ctx context.Context) error {
+	if err := d.validate(); err != nil {
+		return err
+	}
+
+	for _, n := range d.nodes {
+		n.waiting = len(n.deps)
+		n.dependents = nil
+	}
+	for _, n := range d.nodes {
+		for _, dep := range n.deps {
+			d.index[dep].dependents = append(d.index[dep].dependents, n)
+		}
+	}
+
+	return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		// Completions are reported one by one, so the bookkeeping of
+		// dependencies needs no locking
+		completed := make(chan *dagNode)
+		spawn("scheduler", Continue, func(ctx context.Context) error {
+			for remaining := len(d.nodes); remaining > 0; remaining-- {
+				var n *dagNode
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case n = <-completed:
+				}
+				for _, dependent := range n.dependents {
+					dependent.waiting--
+					if dependent.waiting == 0 {
+						d.spawn(spawn, dependent, completed)
+					}
+				}
+			}
+			return nil
+		})
+
+		for _, n := range d.nodes {
+			if n.waiting == 0 {
+				d.spawn(spawn, n, completed)
+			}
+		}
+		return nil
+	})
+}
+
+func (d *DAG) spawn(spawn SpawnFn, n *dagNode, completed chan<- *dagNode) {
+	spawn(n.name, Continue, func(ctx context.Context) error {
+		if err := n.task(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+		case completed <- n:
+		}
+		return nil
+	})
+}
+
+// validate checks that all the dependencies exist and there are no cycles
+func (d *DAG) validate() error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[*dagNode]int{}
+
+	var visit func(n *dagNode) error
+	visit = func(n *dagNode) error {
+		switch state[n] {
+		case visiting:
+			return errors.Errorf("dependency cycle involving task %s", n.name)
+		case visited:
+			return nil
+		}
+		state[n] = visiting
+		for _, dep := range n.deps {
+			depNode, ok := d.index[dep]
+			if !ok {
+				return errors.Errorf("task %s depends on unknown task %s", n.name, dep)
+			}
+			if err := visit(depNode); err != nil {
+				return err
+			}
+		}
+		state[n] = visited
+		return nil
+	}
+
+	for _, n := range d.nodes {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDAG(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) Task {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	dag := NewDAG()
+	dag.Add("package", record("package"), "compile", "lint")
+	dag.Add("compile", record("compile"), "fetch")
+	dag.Add("lint", record("lint"), "fetch")
+	dag.Add("fetch", record("fetch"))
+	dag.Add("independent", record("independent"))
+	require.NoError(t, dag.Run(ctx))
+
+	pos := map[string]int{}
+	for i, name := range order {
+		pos[name] = i
+	}
+	require.Len(t, pos, 5)
+	require.Less(t, pos["fetch"], pos["compile"])
+	require.Less(t, pos["fetch"], pos["lint"])
+	require.Less(t, pos["compile"], pos["package"])
+	require.Less(t, pos["lint"], pos["package"])
+}
+
+func TestDAGFailure(t *testing.T) {
+	ctx := context.Background()
+
+	dag := NewDAG()
+	dag.Add("fetch", func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	dag.Add("compile", func(ctx context.Context) error {
+		t.Fatal("must not be started")
+		return nil
+	}, "fetch")
+	require.EqualError(t, dag.Run(ctx), "oops")
+}
+
+func TestDAGInvalid(t *testing.T) {
+	ctx := context.Background()
+	noop := func(ctx context.Context) error {
+		return nil
+	}
+
+	dag := NewDAG()
+	dag.Add("a", noop, "b")
+	dag.Add("b", noop, "a")
+	require.EqualError(t, dag.Run(ctx), "dependency cycle involving task a")
+
+	dag = NewDAG()
+	dag.Add("a", noop, "missing")
+	require.EqualError(t, dag.Run(ctx), "task a depends on unknown task missing")
+}
@@ -0,0 +1,55 @@
+package parallel
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithDeadline makes the group exit with TimeoutError when the deadline passes.
+// The deadline is reported by Deadline of the group context, unless the parent
+// context has an earlier one.
+func WithDeadline(deadline time.Time) GroupOption {
+	return func(g *Group) {
+		g.deadline = deadline
+	}
+}
+
+// WithTimeout makes the group exit with TimeoutError when the timeout expires,
+// like WithDeadline does
+func WithTimeout(timeout time.Duration) GroupOption {
+	return func(g *Group) {
+		g.timeout = timeout
+	}
+}
+
+// deadlineContext reports the deadline of the group without being closed by
+// it, so the group result is set to TimeoutError before the subtasks see the
+// context closed
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (ctx deadlineContext) Deadline() (time.Time, bool) {
+	if deadline, ok := ctx.Context.Deadline(); ok && deadline.Before(ctx.deadline) {
+		return deadline, true
+	}
+	return ctx.deadline, true
+}
+
+// expire makes the group exit once its deadline passes
+func (g *Group) expire() {
+	timeout := g.deadline.Sub(g.clock.Now())
+	reported := g.timeout
+	if reported == 0 {
+		reported = timeout
+	}
+	timer := g.clock.AfterFunc(timeout, func() {
+		g.Exit(errors.WithStack(TimeoutError{Timeout: reported}))
+	})
+	context.AfterFunc(g.ctx, func() {
+		timer.Stop()
+	})
+}
@@ -0,0 +1,50 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupTimeout(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithTimeout(10*time.Millisecond))
+
+	_, ok := g.Context().Deadline()
+	require.True(t, ok)
+
+	g.Spawn("task", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	var timeoutErr TimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	require.Equal(t, 10*time.Millisecond, timeoutErr.Timeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGroupDeadlineParentCanceled(t *testing.T) {
+	ctx := context.Background()
+	parentCtx, cancel := context.WithCancel(ctx)
+	deadline := time.Now().Add(time.Hour)
+	g := NewGroup(parentCtx, WithDeadline(deadline))
+
+	d, ok := g.Context().Deadline()
+	require.True(t, ok)
+	require.Equal(t, deadline, d)
+
+	g.Spawn("task", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+
+	err := g.Wait()
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, errors.As(err, &TimeoutError{}))
+}
@@ -0,0 +1,91 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DebugHandler returns an HTTP handler rendering the live tree of subtasks of
+// the group, including subgroups, similar to /debug/pprof:
+//
+//	http.Handle("/debug/tasks", parallel.DebugHandler(group))
+//
+// The tree is rendered as HTML, or as JSON if the format=json query parameter
+// is set or the request accepts application/json.
+func DebugHandler(g *Group) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tasks := debugTasks(g.Tasks(), g.clock.Now())
+
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(tasks); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugTemplate.Execute(w, tasks); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+type debugTask struct {
+	Name      string        `json:"name"`
+	Path      string        `json:"path"`
+	ID        string        `json:"id"`
+	OnExit    string        `json:"onExit"`
+	State     string        `json:"state"`
+	Started   *time.Time    `json:"started,omitempty"`
+	Duration  string        `json:"duration,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Subgroups [][]debugTask `json:"subgroups,omitempty"`
+}
+
+func debugTasks(infos []TaskInfo, now time.Time) []debugTask {
+	tasks := make([]debugTask, 0, len(infos))
+	for _, info := range infos {
+		task := debugTask{
+			Name:   info.Name,
+			Path:   info.Path,
+			ID:     fmt.Sprintf("%x", info.ID),
+			OnExit: info.OnExit.String(),
+			State:  info.State.String(),
+		}
+		if !info.Started.IsZero() {
+			started := info.Started
+			task.Started = &started
+			if info.State == TaskRunning {
+				task.Duration = now.Sub(started).String()
+			}
+		}
+		if info.Err != nil {
+			task.Error = info.Err.Error()
+		}
+		for _, sub := range info.Subgroups {
+			task.Subgroups = append(task.Subgroups, debugTasks(sub, now))
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+var debugTemplate = template.Must(template.New("tasks").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Tasks</title></head>
+<body>
+{{define "group"}}<ul>
+{{range .}}<li><b>{{.Name}}</b> [{{.ID}}] {{.State}}, {{.OnExit}}{{if .Duration}}, running for {{.Duration}}{{end}}{{if .Error}}, error: {{.Error}}{{end}}
+{{range .Subgroups}}{{template "group" .}}{{end}}</li>
+{{end}}</ul>{{end}}
+{{template "group" .}}
+</body>
+</html>
+`))
@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	sub := NewSubgroup(g.Spawn, "sub", Continue)
+	started := make(chan struct{})
+	sub.Spawn("worker", Continue, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	rec := httptest.NewRecorder()
+	DebugHandler(g).ServeHTTP(rec, httptest.NewRequest("GET", "/?format=json", nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var tasks []debugTask
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+	require.Len(t, tasks, 1)
+	require.Equal(t, "sub", tasks[0].Name)
+	require.Equal(t, "running", tasks[0].State)
+	require.Len(t, tasks[0].Subgroups, 1)
+	require.Len(t, tasks[0].Subgroups[0], 1)
+	require.Equal(t, "worker", tasks[0].Subgroups[0][0].Name)
+	require.NotEmpty(t, tasks[0].Subgroups[0][0].Duration)
+
+	rec = httptest.NewRecorder()
+	DebugHandler(g).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	require.Contains(t, rec.Body.String(), "<b>worker</b>")
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
@@ -0,0 +1,68 @@
+package parallel
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/pkg/errors"
+)
+
+type deferredFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Defer registers a function to be called once all the subtasks have
+// finished, before Wait returns. The functions are called in the reverse
+// order of registration, like deferred calls in Go, so resources may be torn
+// down in the reverse order of their creation.
+//
+// The context passed to fn carries the values of the group context, but it
+// isn't closed on group shutdown. The errors returned by the functions are
+// joined with the group result.
+func (g *Group) Defer(name string, fn func(ctx context.Context) error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.deferred = append(g.deferred, deferredFunc{name: name, fn: fn})
+}
+
+// runDeferred calls the functions registered by Defer and adds their errors
+// to the group result
+func (g *Group) runDeferred() {
+	g.deferMu.Lock()
+	defer g.deferMu.Unlock()
+
+	g.mu.Lock()
+	deferred := g.deferred
+	g.deferred = nil
+	g.mu.Unlock()
+
+	if len(deferred) == 0 {
+		return
+	}
+
+	ctx := context.WithoutCancel(g.ctx)
+	var errs []error
+	for i := len(deferred) - 1; i >= 0; i-- {
+		d := deferred[i]
+		if err := runTask(ctx, g.taskPath(d.name), d.fn); err != nil {
+			errs = append(errs, errors.WithMessagef(err, "deferred %s", g.taskPath(d.name)))
+		}
+	}
+	if len(errs) == 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.err != nil {
+		errs = append([]error{g.err}, errs...)
+	}
+	if len(errs) == 1 {
+		g.err = errs[0]
+	} else {
+		g.err = stderrors.Join(errs...)
+	}
+}
@@ -0,0 +1,41 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefer(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	var order []string
+	g.Defer("db", func(ctx context.Context) error {
+		order = append(order, "db")
+		require.NoError(t, ctx.Err())
+		return errors.New("db failed")
+	})
+	g.Defer("cache", func(ctx context.Context) error {
+		order = append(order, "cache")
+		return nil
+	})
+	g.Defer("broken", func(ctx context.Context) error {
+		order = append(order, "broken")
+		panic("oops")
+	})
+	g.Spawn("task", Fail, func(ctx context.Context) error {
+		order = append(order, "task")
+		return errors.New("task failed")
+	})
+
+	err := g.Wait()
+	require.Equal(t, []string{"task", "broken", "cache", "db"}, order)
+	require.EqualError(t, err, "task failed\ndeferred broken: panic: oops\ndeferred db: db failed")
+
+	// Deferred functions run once
+	require.Equal(t, err, g.Wait())
+	require.Len(t, order, 4)
+}
@@ -0,0 +1,174 @@
+package parallel
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotAttached is returned by Group.Detach if the group is not a subgroup
+// attached to a parent group
+var ErrNotAttached = errors.New("group not attached")
+
+// Detach detaches the subgroup created by NewSubgroup, or attached by
+// Reattach, from its parent group, so the lifetime of the subgroup is
+// controlled by ctx from now on. The subtask owning the subgroup in the parent
+// group finishes returning nil, while the subtasks of the subgroup keep
+// running.
+//
+// Use it to hand over a running subsystem, e.g. during reload of the
+// configuration of the parent.
+//
+// Detach may also be called on a detached group to replace its controlling
+// context. ErrNotAttached is returned if the group has been created neither by
+// NewSubgroup nor by Reattach, or if it's already shutting down.
+func (g *Group) Detach(ctx context.Context) error {
+	g.mu.Lock()
+	detach := g.unlink
+	g.unlink = nil
+	g.mu.Unlock()
+
+	if detach == nil || !detach() {
+		return ErrNotAttached
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		g.cancel(context.Cause(ctx))
+	})
+	g.mu.Lock()
+	g.unlink = stop
+	g.mu.Unlock()
+	return nil
+}
+
+// Reattach attaches the group detached by Detach to the parent group owning
+// the spawn function, the same way NewSubgroup does. The context passed to
+// Detach doesn't control the group anymore. If the group is still attached,
+// it is moved to the new parent.
+//
+// ErrNotAttached is returned under the same conditions as by Detach.
+func (g *Group) Reattach(spawn SpawnFn, name string, onExit OnExit) error {
+	g.mu.Lock()
+	detach := g.unlink
+	g.unlink = nil
+	g.mu.Unlock()
+
+	if detach == nil || !detach() {
+		return ErrNotAttached
+	}
+
+	linked := make(chan struct{})
+	var ran bool
+	spawn(name, onExit, func(ctx context.Context) error {
+		if ran {
+			return Permanent(errors.Errorf("subgroup %s has finished and can't be run again", g.path))
+		}
+		ran = true
+		// The group is moved from the subtask owning it so far
+		g.unlist(false)
+		if parent, ok := ctx.Value(subtaskKey).(*subtask); ok {
+			g.listIn(parent)
+		}
+		g.ownedBy(ctx)
+		detached := g.link(ctx)
+		close(linked)
+		return g.own(ctx, detached)
+	})
+	<-linked
+	return nil
+}
+
+// link links the lifetime of the group to ctx of the subtask owning it. The
+// returned channel is closed when the group is detached.
+func (g *Group) link(ctx context.Context) <-chan struct{} {
+	detached := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		g.cancel(context.Cause(ctx))
+	})
+
+	g.mu.Lock()
+	g.unlink = func() bool {
+		if g.ctx.Err() != nil || !stop() {
+			return false
+		}
+		if owner, ok := ctx.Value(subtaskKey).(*subtask); ok {
+			owner.group.mu.Lock()
+			owner.detached = true
+			owner.group.mu.Unlock()
+		}
+		close(detached)
+		return true
+	}
+	g.mu.Unlock()
+	return detached
+}
+
+// ownedBy marks the group as the subgroup run by the subtask owning ctx, so
+// the subtasks of the group are restarted instead of the subtask, see
+// Group.Restart
+func (g *Group) ownedBy(ctx context.Context) {
+	if owner, ok := ctx.Value(subtaskKey).(*subtask); ok {
+		owner.group.mu.Lock()
+		owner.owned = g
+		owner.group.mu.Unlock()
+	}
+}
+
+// own waits like Complete does, but returns nil once the group is detached
+func (g *Group) own(ctx context.Context, detached <-chan struct{}) error {
+	select {
+	case <-ctx.Done():
+	case <-g.ctx.Done():
+	case <-detached:
+		return nil
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// SpawnDetached spawns a subtask which isn't bound to the lifetime of the
+// group. Its context carries the values of the group context, but isn't closed
+// when the group shuts down, and Wait doesn't wait for it. Use it for
+// fire-and-forget jobs, like audit logging, which must outlive the group.
+//
+// The error returned by the subtask is logged. Use WaitDetached to wait for the
+// detached subtasks.
+func (g *Group) SpawnDetached(name string, task Task) {
+	_, id := g.newTaskID()
+	path := g.taskPath(name)
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		task = g.middleware[i](task)
+	}
+
+	log := g.log.Named(name)
+	ctx := withLogger(context.WithValue(context.WithoutCancel(g.ctx), taskIDKey, id), log)
+	log.Debug("Detached task spawned", "id", hexID(id))
+
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+
+		if err := runTask(ctx, path, task); err != nil {
+			log.Error("Detached task failed", "error", err)
+		}
+	}()
+}
+
+// WaitDetached waits for the subtasks spawned by SpawnDetached to finish, or
+// until ctx closes, in which case ctx.Err() is returned
+func (g *Group) WaitDetached(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
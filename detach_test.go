@@ -0,0 +1,80 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetach(t *testing.T) {
+	ctx := context.Background()
+
+	parent := NewGroup(ctx)
+	sub := NewSubgroup(parent.Spawn, "sub", Fail)
+	sub.Spawn("worker", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.ErrorIs(t, parent.Detach(ctx), ErrNotAttached)
+	require.NoError(t, sub.Detach(ctx))
+	require.NoError(t, parent.Wait())
+	parent.Exit(nil)
+	require.NoError(t, sub.Context().Err())
+
+	newParent := NewGroup(ctx)
+	require.NoError(t, sub.Reattach(newParent.Spawn, "sub", Fail))
+	newParent.Exit(nil)
+	require.NoError(t, newParent.Wait())
+	require.ErrorIs(t, sub.Context().Err(), context.Canceled)
+	require.ErrorIs(t, sub.Detach(ctx), ErrNotAttached)
+}
+
+func TestDetachContext(t *testing.T) {
+	ctx := context.Background()
+
+	parent := NewGroup(ctx)
+	sub := NewSubgroup(parent.Spawn, "sub", Continue)
+	sub.Spawn("worker", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	detachCtx, cancel := context.WithCancel(ctx)
+	require.NoError(t, sub.Detach(detachCtx))
+	require.NoError(t, parent.Wait())
+
+	cancel()
+	require.ErrorIs(t, sub.Wait(), context.Canceled)
+}
+
+func TestSpawnDetached(t *testing.T) {
+	ctx := context.Background()
+	type key struct{}
+	g := NewGroup(context.WithValue(ctx, key{}, "value"))
+
+	release := make(chan struct{})
+	result := make(chan error, 1)
+	g.SpawnDetached("audit", func(ctx context.Context) error {
+		<-release
+		if ctx.Value(key{}) != "value" {
+			result <- errors.New("value not inherited")
+		}
+		result <- ctx.Err()
+		return nil
+	})
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, g.WaitDetached(waitCtx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, g.WaitDetached(ctx))
+	require.NoError(t, <-result)
+}
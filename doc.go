@@ -0,0 +1,18 @@
+// Package parallel implements structured concurrency for Go, see Run and
+// Group.
+//
+// # Logging
+//
+// Groups log through Logger, which is slog.Default() unless configured by
+// WithLogger or SetDefaultLogger. A zap logger stored in the context by
+// github.com/outofforest/logger is not used by groups anymore, and logger.Get
+// called by a subtask doesn't return a logger named after the subtask, unless
+// the group logs through the adapter from
+// github.com/outofforest/parallel/zaplogger:
+//
+//	parallel.Run(ctx, start, parallel.WithLogger(zaplogger.FromContext(ctx)))
+//
+// or, for all the groups of the process:
+//
+//	parallel.SetDefaultLogger(zaplogger.New(log))
+package parallel
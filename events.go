@@ -0,0 +1,110 @@
+package parallel
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType is an enumeration of lifecycle events reported to an Observer
+type EventType int
+
+const (
+	// EventTaskSpawned means a subtask has been spawned
+	EventTaskSpawned EventType = iota
+
+	// EventTaskStarted means a subtask has started running. In Restart mode it
+	// is reported for every run.
+	EventTaskStarted
+
+	// EventTaskFinished means a run of a subtask has finished, Err is the
+	// result of the run
+	EventTaskFinished
+
+	// EventTaskPanicked means a subtask has panicked, Err is the PanicError.
+	// EventTaskFinished follows.
+	EventTaskPanicked
+
+	// EventGroupExited means the group has started shutting down, Err is the
+	// cause, see Group.Cause
+	EventGroupExited
+
+	// EventTaskStuck means a subtask hasn't finished in time after the group
+	// context had been closed, see WithWatchdog
+	EventTaskStuck
+
+	// EventTaskSilent means a subtask has stopped calling Heartbeat, see
+	// WithLiveness
+	EventTaskSilent
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventTaskSpawned:
+		return "spawned"
+	case EventTaskStarted:
+		return "started"
+	case EventTaskFinished:
+		return "finished"
+	case EventTaskPanicked:
+		return "panicked"
+	case EventGroupExited:
+		return "exited"
+	case EventTaskStuck:
+		return "stuck"
+	case EventTaskSilent:
+		return "silent"
+	default:
+		return fmt.Sprintf("invalid EventType: %d", t)
+	}
+}
+
+// Event is a lifecycle event of a group or of its subtask
+type Event struct {
+	// Type is the type of the event
+	Type EventType
+
+	// Time is the time the event happened
+	Time time.Time
+
+	// Group is the hierarchical name of the group, see GroupName
+	Group string
+
+	// Task is the hierarchical name of the subtask, see TaskInfo.Path. It is
+	// empty for group events.
+	Task string
+
+	// ID is the ID of the subtask, 0 for group events
+	ID int64
+
+	// Labels are the labels of the subtask, see WithLabels
+	Labels map[string]string
+
+	// Err is the error related to the event, see EventType
+	Err error
+
+	// Stack is the stack trace of the goroutine running the subtask, set for
+	// EventTaskStuck and EventTaskSilent
+	Stack string
+}
+
+// Observer receives lifecycle events. It is called synchronously from the
+// goroutine causing the event, so it should return quickly. It may be called
+// concurrently.
+type Observer func(event Event)
+
+// WithObserver makes the group and its subgroups report lifecycle events to
+// the observer
+func WithObserver(o Observer) GroupOption {
+	return func(g *Group) {
+		g.observer = o
+	}
+}
+
+func (g *Group) observe(event Event) {
+	if g.observer == nil {
+		return
+	}
+	event.Time = g.clock.Now()
+	event.Group = g.path
+	g.observer(event)
+}
@@ -0,0 +1,49 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	events := map[string][]EventType{}
+	var exits []Event
+	observer := func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if event.Type == EventGroupExited {
+			exits = append(exits, event)
+			return
+		}
+		events[event.Task] = append(events[event.Task], event.Type)
+	}
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		sub := NewSubgroup(spawn, "sub", Continue)
+		sub.Spawn("ok", Exit, func(ctx context.Context) error {
+			return nil
+		})
+		<-sub.Done()
+		spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	}, WithObserver(observer))
+	require.IsType(t, PanicError{}, err)
+
+	require.Equal(t, []EventType{EventTaskSpawned, EventTaskStarted, EventTaskFinished}, events["sub.ok"])
+	require.Equal(t, []EventType{EventTaskSpawned, EventTaskStarted, EventTaskPanicked, EventTaskFinished}, events["doomed"])
+
+	require.Len(t, exits, 2)
+	require.Equal(t, "sub", exits[0].Group)
+	require.ErrorIs(t, exits[0].Err, ErrGroupExited)
+	require.Empty(t, exits[1].Group)
+	require.IsType(t, PanicError{}, exits[1].Err)
+}
@@ -0,0 +1,67 @@
+package parallel
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+)
+
+// ForEach calls fn for every item, running at most limit calls concurrently.
+// A non-positive limit means no limit.
+//
+// The calls are subtasks of a group configured by opts. By default, the first
+// error cancels the context passed to the running calls, the remaining items
+// are skipped and the error is returned. If WithErrorAggregation is passed,
+// all the items are processed regardless of errors, and errors of all the
+// failed calls are returned joined by errors.Join.
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error, opts ...GroupOption) error {
+	return forEachIndex(ctx, len(items), limit, func(ctx context.Context, i int) error {
+		return fn(ctx, items[i])
+	}, opts...)
+}
+
+// Map calls fn for every item like ForEach does, and returns the results in
+// the order of items.
+//
+// If an error is returned, the results of failed and skipped calls are zero
+// values.
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error), opts ...GroupOption) ([]R, error) {
+	results := make([]R, len(items))
+	err := forEachIndex(ctx, len(items), limit, func(ctx context.Context, i int) error {
+		var err error
+		results[i], err = fn(ctx, items[i])
+		return err
+	}, opts...)
+	return results, err
+}
+
+// forEachIndex calls fn for every index from 0 to n-1, see ForEach
+func forEachIndex(ctx context.Context, n, limit int, fn func(ctx context.Context, i int) error, opts ...GroupOption) error {
+	g := NewGroup(ctx, append([]GroupOption{WithMaxConcurrent(limit)}, opts...)...)
+
+	var mu sync.Mutex
+	var errs []error
+	for i := 0; i < n; i++ {
+		i := i
+		g.Spawn(fmt.Sprintf("item-%d", i), Continue, func(ctx context.Context) error {
+			// The group has already failed, skip the item
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			err := fn(ctx, i)
+			if err != nil && g.aggregateErrors {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return nil
+			}
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return stderrors.Join(errs...)
+}
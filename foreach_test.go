@@ -0,0 +1,77 @@
+package parallel
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	ctx := context.Background()
+
+	var sum int64
+	err := ForEach(ctx, []int64{1, 2, 3, 4, 5}, 2, func(ctx context.Context, item int64) error {
+		atomic.AddInt64(&sum, item)
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 15, sum)
+}
+
+func TestForEachFirstError(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	err := ForEach(ctx, []int{1, 2, 3, 4, 5}, 1, func(ctx context.Context, item int) error {
+		atomic.AddInt32(&calls, 1)
+		if item == 2 {
+			return errors.New("oops")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "oops")
+	require.EqualValues(t, 2, calls)
+}
+
+func TestForEachAllErrors(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	err := ForEach(ctx, []int{1, 2, 3, 4, 5}, 2, func(ctx context.Context, item int) error {
+		atomic.AddInt32(&calls, 1)
+		if item%2 == 0 {
+			return errors.Errorf("oops%d", item)
+		}
+		return nil
+	}, WithErrorAggregation())
+	require.ErrorContains(t, err, "oops2")
+	require.ErrorContains(t, err, "oops4")
+	require.EqualValues(t, 5, calls)
+}
+
+func TestMap(t *testing.T) {
+	ctx := context.Background()
+
+	results, err := Map(ctx, []int{1, 2, 3, 4, 5}, 3, func(ctx context.Context, item int) (string, error) {
+		return strconv.Itoa(item * item), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "4", "9", "16", "25"}, results)
+}
+
+func TestMapError(t *testing.T) {
+	ctx := context.Background()
+
+	results, err := Map(ctx, []int{1, 2, 3}, 1, func(ctx context.Context, item int) (int, error) {
+		if item == 2 {
+			return 0, errors.New("oops")
+		}
+		return item, nil
+	})
+	require.EqualError(t, err, "oops")
+	require.Equal(t, []int{1, 0, 0}, results)
+}
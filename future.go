@@ -0,0 +1,76 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the result of a subtask spawned with SpawnResult. It becomes
+// available when the subtask finishes.
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// SpawnResult spawns a subtask which produces a value, using the given spawn
+// function. The returned Future delivers the value and error returned by fn.
+//
+// The error is also handled by the parent task as usual, see the documentation
+// for OnExit. If fn panics, the Future reports the panic as PanicError.
+//
+// In Restart mode the Future delivers the result of the first run only.
+//
+// Example:
+//
+//	err := parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+//	    sum := parallel.SpawnResult(spawn, "sum", parallel.Continue, func(ctx context.Context) (int, error) {
+//	        return compute(ctx)
+//	    })
+//	    spawn("report", parallel.Exit, func(ctx context.Context) error {
+//	        v, err := sum.Wait(ctx)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        return report(ctx, v)
+//	    })
+//	    return nil
+//	})
+func SpawnResult[T any](spawn SpawnFn, name string, onExit OnExit, fn func(ctx context.Context) (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	var once sync.Once
+	spawn(name, onExit, func(ctx context.Context) error {
+		var value T
+		err := runTask(ctx, taskPath(ctx, name), func(ctx context.Context) error {
+			var err error
+			value, err = fn(ctx)
+			return err
+		})
+		once.Do(func() {
+			f.value, f.err = value, err
+			close(f.done)
+		})
+		return err
+	})
+	return f
+}
+
+// Done returns a channel that closes when the subtask finishes
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the subtask finishes, then returns its value and error.
+//
+// If ctx closes first, Wait returns the zero value and ctx.Err(). Passing the
+// context of a task running in the same group makes Wait return as soon as the
+// group shuts down.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,93 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFutureValue(t *testing.T) {
+	ctx := context.Background()
+	var value int
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		f := SpawnResult(spawn, "compute", Continue, func(ctx context.Context) (int, error) {
+			return 42, nil
+		})
+		spawn("consume", Continue, func(ctx context.Context) error {
+			var err error
+			value, err = f.Wait(ctx)
+			return err
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+}
+
+func TestFutureError(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+	f := SpawnResult(g.Spawn, "compute", Continue, func(ctx context.Context) (int, error) {
+		return 0, errors.New("oops")
+	})
+	_, err := f.Wait(ctx)
+	require.EqualError(t, err, "oops")
+	require.EqualError(t, g.Wait(), "oops")
+}
+
+func TestFuturePanic(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+	f := SpawnResult(g.Spawn, "compute", Continue, func(ctx context.Context) (int, error) {
+		panic("oops")
+	})
+	_, err := f.Wait(ctx)
+	require.IsType(t, PanicError{}, err)
+	require.IsType(t, PanicError{}, g.Wait())
+}
+
+func TestFutureWaitCanceled(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+	f := SpawnResult(g.Spawn, "compute", Fail, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := f.Wait(waitCtx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	g.Exit(nil)
+	_, err = f.Wait(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, g.Wait())
+}
+
+func TestFutureRestart(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithRestartBackoff(Backoff{Initial: time.Millisecond, Max: time.Millisecond}))
+
+	var runs atomic.Int32
+	f := SpawnResult(g.Spawn, "compute", Restart, func(ctx context.Context) (int, error) {
+		return int(runs.Add(1)), nil
+	})
+	value, err := f.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+
+	for runs.Load() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+
+	value, err = f.Wait(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+}
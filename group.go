@@ -2,18 +2,33 @@ package parallel
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/outofforest/logger"
 	"github.com/pkg/errors"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
 )
 
 var nextTaskID int64 = 0x0bace1d000000000
 
+// ErrGroupClosed is returned by Group.SpawnE if the group doesn't accept
+// subtasks anymore, because it has been closed by Group.Close, or it has
+// finished and was created with WithStrict
+var ErrGroupClosed = errors.New("group closed")
+
+// ErrGroupExited is the cancellation cause of the group context if the group
+// is shutting down without an error, either because of an Exit call or because
+// a subtask in Exit mode finished
+var ErrGroupExited = errors.New("group exited")
+
 // Group is a facility for running a task with several subtasks without
 // inversion of control. For most ordinary use cases, use Run instead.
 //
@@ -31,29 +46,252 @@ var nextTaskID int64 = 0x0bace1d000000000
 // is controlled by test setup and teardown functions.
 type Group struct {
 	ctx    context.Context
-	cancel context.CancelFunc
+	cancel context.CancelCauseFunc
+	name   string
+	path   string
+
+	log             Logger
+	maxConcurrent   int
+	metrics         *metrics
+	restartBackoff  Backoff
+	aggregateErrors bool
+	maxErrors       int
+	spawnLimiter    *rate.Limiter
+	onPanic         OnPanicFunc
+	repanic         bool
+	stackCapture    *StackCapture
+	observer        Observer
+	middleware      []Middleware
+	watchdog        time.Duration
+	liveness        time.Duration
+	clock           Clock
+	synchronous     bool
+	reuse           time.Duration
+	taskErrors      bool
+	deadline        time.Time
+	timeout         time.Duration
+	breaker         CircuitBreaker
+	reloadSignal    os.Signal
+	reloadTasks     []string
+	stopReload      func()
+	created         time.Time
+	slowTask        time.Duration
+	strict          bool
+	sequentialIDs   bool
+	owner           *subtask
+	lanes           map[string]*lane
+	fields          []interface{}
+	idle            chan *subtask
+
+	// Held while the deferred functions run
+	deferMu sync.Mutex
+
+	// Counts the subtasks spawned with WithDeterministicIDs
+	lastID atomic.Int64
+
+	// Counts the running subtasks spawned by SpawnDetached
+	background sync.WaitGroup
+
+	mu       sync.Mutex
+	running  int
+	active   int
+	tasks    map[int64]*subtask
+	finished []*subtask
+	retired  int
+	history  int
+	queue    priorityQueue[*subtask]
+	done     chan struct{}
+	closing  bool
+	closed   bool
+	paused   bool
+	pausing  chan struct{}
+	unpause  func() bool
+	deferred []deferredFunc
+	phases   []*phase
+	single   map[string]*TaskHandle
+	settled  chan struct{}
+	excused  []error
+	unlink   func() bool
+	listedBy *subtask
+	inline   []*subtask
+	counts   taskCounts
+	err      error
+	errs     []error
+}
+
+// subtask is a task spawned in a group
+type subtask struct {
+	ctx    context.Context
+	group  *Group
+	key    int64
+	id     int64
+	name   string
+	path   string
+	onExit OnExit
+	task   Task
+
+	onExitFunc OnExitFunc
+
+	output       *output
+	prefixOutput bool
 
-	mu      sync.Mutex
-	running int
-	done    chan struct{}
-	closing bool
-	err     error
+	timeout  time.Duration
+	priority int
+	labels   map[string]string
+	handle   *TaskHandle
+	attached bool
+	beat     atomic.Pointer[heartbeat]
+
+	phaseName string
+	phase     *phase
+	laneName  string
+	lane      *lane
+	singleton bool
+
+	// Protected by the mutex of the group
+	state     TaskState
+	started   time.Time
+	duration  time.Duration
+	err       error
+	detached  bool
+	lastErr   error
+	restarts  int
+	rerun     context.CancelCauseFunc
+	reloading bool
+	stopped   chan struct{}
+	ready     bool
+	owned     *Group
+	subgroups []*Group
 }
 
 // NewGroup creates a new Group controlled by the given context
-func NewGroup(ctx context.Context) *Group {
-	g := new(Group)
-	g.ctx, g.cancel = context.WithCancel(ctx)
-	g.done = make(chan struct{})
-	close(g.done)
+func NewGroup(ctx context.Context, opts ...GroupOption) *Group {
+	g := &Group{
+		restartBackoff: DefaultBackoff,
+		history:        defaultTaskHistory,
+		tasks:          map[int64]*subtask{},
+		pausing:        make(chan struct{}),
+		single:         map[string]*TaskHandle{},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.log == nil {
+		g.log = loggerFromContext(ctx)
+	}
+	if g.name != "" {
+		g.log = g.log.Named(g.name)
+	}
+	if len(g.fields) > 0 {
+		g.log = g.log.With(g.fields...)
+	}
+	if g.onPanic != nil {
+		ctx = context.WithValue(ctx, onPanicKey, g.onPanic)
+	}
+	if g.stackCapture != nil {
+		ctx = context.WithValue(ctx, stackCaptureKey, g.stackCapture)
+	}
+	if parent, ok := ctx.Value(middlewareKey).([]Middleware); ok {
+		g.middleware = append(append([]Middleware(nil), parent...), g.middleware...)
+	}
+	if len(g.middleware) > 0 {
+		ctx = context.WithValue(ctx, middlewareKey, g.middleware)
+	}
+	if g.clock == nil {
+		g.clock = clockFromContext(ctx)
+	} else {
+		ctx = ContextWithClock(ctx, g.clock)
+	}
+	if g.observer == nil {
+		g.observer, _ = ctx.Value(observerKey).(Observer)
+	} else {
+		ctx = context.WithValue(ctx, observerKey, g.observer)
+	}
+	g.created = g.clock.Now()
+	base := withLogger(context.WithValue(ctx, groupKey, g), g.log)
+	if g.timeout > 0 {
+		g.deadline = g.clock.Now().Add(g.timeout)
+	}
+	if !g.deadline.IsZero() {
+		base = deadlineContext{Context: base, deadline: g.deadline}
+	}
+	g.ctx, g.cancel = context.WithCancelCause(base)
+	if !g.deadline.IsZero() {
+		g.expire()
+	}
+
+	if parent, ok := ctx.Value(subtaskKey).(*subtask); ok {
+		// The path of the group is the path of the task owning it
+		g.path = parent.path
+		g.owner = parent
+		g.listIn(parent)
+	}
+	if g.name != "" {
+		g.path = g.taskPath(g.name)
+	}
+	if g.watchdog > 0 {
+		context.AfterFunc(g.ctx, g.watch)
+	}
+	if g.reloadSignal != nil {
+		g.watchReload()
+	}
 	return g
 }
 
+// listIn adds the group to the subgroups of the subtask, reported by Tasks
+func (g *Group) listIn(parent *subtask) {
+	parent.group.mu.Lock()
+	parent.subgroups = append(parent.subgroups, g)
+	parent.group.mu.Unlock()
+
+	g.mu.Lock()
+	g.listedBy = parent
+	g.mu.Unlock()
+}
+
+// unlist removes the group from the subgroups of the subtask listing it. If
+// keepOwned is true, the subgroup run by the subtask, see NewSubgroup, is
+// kept, so it's reported along with the finished subtask.
+//
+// Finished groups are unlisted by Wait, so a subtask creating groups
+// repeatedly, e.g. by calling ForEach in a loop, doesn't retain all of them.
+func (g *Group) unlist(keepOwned bool) {
+	g.mu.Lock()
+	parent := g.listedBy
+	g.mu.Unlock()
+
+	if parent == nil {
+		return
+	}
+	parent.group.mu.Lock()
+	defer parent.group.mu.Unlock()
+
+	if keepOwned && parent.owned == g {
+		return
+	}
+	parent.subgroups = slices.DeleteFunc(parent.subgroups, func(sub *Group) bool {
+		return sub == g
+	})
+
+	g.mu.Lock()
+	g.listedBy = nil
+	g.mu.Unlock()
+}
+
 // NewSubgroup creates a new Group nested within another. The spawn argument is
 // the spawn function of the parent group.
 //
 // The subgroup's context is inherited from the parent group. The entire
-// subgroup is treated as a task in the parent group.
+// subgroup is treated as a task in the parent group, until it is detached, see
+// Group.Detach.
+//
+// The fields are added to the logger of the subgroup, see Logger.With.
+//
+// The subtask owning the subgroup runs only once: the subtasks of the
+// subgroup are spawned by the caller, so they can't be spawned again. Don't
+// spawn it in Restart mode or with WithRetry, its second run fails with a
+// permanent error. Group.Restart and WithReload restart the subtasks of the
+// subgroup instead.
 //
 // Example within parallel.Run:
 //
@@ -74,15 +312,25 @@ func NewGroup(ctx context.Context) *Group {
 //	subgroup := parallel.NewSubgroup(group.Spawn, "updater")
 //	subgroup.Spawn(...)
 //	subgroup.Spawn(...)
-func NewSubgroup(spawn SpawnFn, name string, onExit OnExit, fields ...zapcore.Field) *Group {
+func NewSubgroup(spawn SpawnFn, name string, onExit OnExit, fields ...interface{}) *Group {
 	ch := make(chan *Group)
+	var sub *Group
 	spawn(name, onExit, func(ctx context.Context) error {
+		if sub != nil {
+			// The subtasks of the subgroup were spawned by the caller, so the
+			// subgroup can't be started again
+			return Permanent(errors.Errorf("subgroup %s has finished and can't be run again", sub.path))
+		}
 		if len(fields) > 0 {
-			ctx = logger.With(ctx, fields...)
+			ctx = withLogger(ctx, loggerFromContext(ctx).With(fields...))
 		}
-		g := NewGroup(ctx)
-		ch <- g
-		return g.Complete(ctx)
+		// The lifetime of the subgroup is linked to ctx, so it may be
+		// detached later
+		sub = NewGroup(context.WithoutCancel(ctx))
+		sub.ownedBy(ctx)
+		detached := sub.link(ctx)
+		ch <- sub
+		return sub.own(ctx, detached)
 	})
 	return <-ch
 }
@@ -93,53 +341,560 @@ func (g *Group) Context() context.Context {
 	return g.ctx
 }
 
+// FromContext returns the group owning ctx, which is the context of the group
+// or of one of its subtasks, so code deep in the call chain may spawn siblings
+// of its subtask without being passed the spawn function. False is returned
+// if ctx doesn't belong to any group.
+func FromContext(ctx context.Context) (*Group, bool) {
+	g, ok := ctx.Value(groupKey).(*Group)
+	return g, ok
+}
+
 // Spawn spawns a subtask. See documentation for SpawnFn.
 //
 // When a subtask finishes, it sets the result of the group if it's not already
 // set (unless the task returns nil and its OnExit mode is Continue).
+//
+// If the group was created with WithMaxConcurrent and the limit is reached,
+// the subtask is queued and started later. Spawn only blocks if the group was
+// created with WithSpawnRate and the rate is exceeded, or with WithSynchronous.
+//
+// Spawning a subtask in a closed group is a bug, so Spawn panics with
+// ErrGroupClosed in that case. Use SpawnE to handle that case gracefully.
 func (g *Group) Spawn(name string, onExit OnExit, task Task) {
-	id := atomic.AddInt64(&nextTaskID, 1)
+	g.SpawnWith(name, onExit, task)
+}
+
+// SpawnWith spawns a subtask like Spawn does, configured by opts, see
+// SpawnOption
+func (g *Group) SpawnWith(name string, onExit OnExit, task Task, opts ...SpawnOption) {
+	if err := g.SpawnE(name, onExit, task, opts...); err != nil {
+		panic(errors.WithMessagef(err, "spawning task %s", g.taskPath(name)))
+	}
+}
+
+// SpawnE spawns a subtask like SpawnWith does, but returns ErrGroupClosed if
+// the group has been closed, or an error if the subtask is assigned to a lane
+// the group doesn't define, see InLane
+func (g *Group) SpawnE(name string, onExit OnExit, task Task, opts ...SpawnOption) error {
+	st := g.newSubtask(name, onExit, task, opts)
+	if st.laneName != "" && st.lane == nil {
+		return errors.Errorf("lane %s is not defined in the group", st.laneName)
+	}
 
 	g.mu.Lock()
-	if g.running == 0 {
-		g.done = make(chan struct{})
+	if g.closed {
+		g.mu.Unlock()
+		return ErrGroupClosed
 	}
-	g.running++
+	queued := g.register(st)
 	g.mu.Unlock()
 
-	log := logger.Get(g.ctx).Named(name)
-	log.Debug("Task spawned", zap.String("id", fmt.Sprintf("%x", id)), zap.Stringer("onExit", onExit))
+	g.start(st, queued)
+	return nil
+}
+
+// SpawnMany spawns the subtasks like Spawn does, registering all of them at
+// once. The subtasks are named after the prefix and their index in tasks, e.g.
+// shard-0, shard-1 and so on.
+func (g *Group) SpawnMany(prefix string, onExit OnExit, tasks []Task) {
+	sts := make([]*subtask, 0, len(tasks))
+	for i, task := range tasks {
+		sts = append(sts, g.newSubtask(fmt.Sprintf("%s-%d", prefix, i), onExit, task, nil))
+	}
+
+	queued := make([]bool, len(sts))
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		panic(errors.WithMessagef(ErrGroupClosed, "spawning tasks %s", g.taskPath(prefix)))
+	}
+	for i, st := range sts {
+		queued[i] = g.register(st)
+	}
+	g.mu.Unlock()
+
+	for i, st := range sts {
+		g.start(st, queued[i])
+	}
+}
+
+// newSubtask prepares the subtask to be registered in the group
+func (g *Group) newSubtask(name string, onExit OnExit, task Task, opts []SpawnOption) *subtask {
+	if g.spawnLimiter != nil {
+		// The error means the group is shutting down, the subtask is spawned
+		// anyway to keep the accounting of OnExit modes
+		_ = g.spawnLimiter.Wait(g.ctx)
+	}
+
+	key, id := g.newTaskID()
+
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		task = g.middleware[i](task)
+	}
+
+	st := &subtask{
+		group:  g,
+		key:    key,
+		id:     id,
+		name:   name,
+		path:   g.taskPath(name),
+		onExit: onExit,
+		task:   task,
+	}
+	for _, opt := range opts {
+		opt(st)
+	}
+	if st.laneName != "" {
+		st.lane = g.lanes[st.laneName]
+	}
+	base := g.ctx
+	if st.phaseName != "" {
+		g.mu.Lock()
+		st.phase = g.phase(st.phaseName)
+		g.mu.Unlock()
+		base = st.phase.ctx
+	}
+	if st.output != nil || st.prefixOutput {
+		st.output = outputFor(base, st)
+		base = context.WithValue(base, outputKey, st.output)
+	}
+	st.ctx = withLogger(context.WithValue(context.WithValue(base, taskIDKey, id), subtaskKey, st), g.log.Named(name))
+	if st.handle != nil {
+		var cancel context.CancelFunc
+		st.ctx, cancel = context.WithCancel(st.ctx)
+		st.handle.setCancel(cancel)
+	}
+	return st
+}
+
+// register adds the subtask to the group, queueing it if it can't be started
+// right away. The mutex must be held.
+func (g *Group) register(st *subtask) (queued bool) {
+	g.running++
+	g.counts.spawned++
+	g.tasks[st.id] = st
+	st.phase.add()
+	if st.lane != nil {
+		queued = g.paused || st.lane.full()
+		if queued {
+			st.lane.queue.Push(st, st.priority)
+		} else {
+			st.lane.active++
+		}
+	} else {
+		queued = g.paused || g.maxConcurrent > 0 && g.active >= g.maxConcurrent
+		if queued {
+			g.queue.Push(st, st.priority)
+		} else {
+			g.active++
+		}
+	}
+	if !queued {
+		st.state = TaskRunning
+	}
+	return queued
+}
+
+// start reports the registered subtask and runs it unless it's queued
+func (g *Group) start(st *subtask, queued bool) {
+	g.metrics.taskSpawned(st.path)
+	g.observe(Event{Type: EventTaskSpawned, Task: st.path, ID: st.id, Labels: st.labels})
+	if log := loggerFromContext(st.ctx); debugEnabled(log) {
+		log.Debug("Task spawned", "id", hexID(st.id), "onExit", st.onExit, "queued", queued)
+	}
+
+	if queued {
+		return
+	}
+	switch {
+	case g.synchronous:
+		g.runTask(st.ctx, st.key, st)
+	case g.handOff(st):
+	case g.idle != nil:
+		go g.worker(st)
+	default:
+		go g.runTask(st.ctx, st.key, st)
+	}
+}
+
+// launch starts the subtask taken from the queue. In synchronous mode the
+// subtask is only scheduled, to be run by runScheduled. The mutex must be held.
+func (g *Group) launch(st *subtask) {
+	st.state = TaskRunning
+	if g.synchronous {
+		g.inline = append(g.inline, st)
+		return
+	}
+	switch {
+	case g.handOff(st):
+	case g.idle != nil:
+		go g.worker(st)
+	default:
+		go g.runTask(st.ctx, st.key, st)
+	}
+}
+
+// handOff passes the subtask to an idle goroutine, if there is one, see
+// WithGoroutineReuse
+func (g *Group) handOff(st *subtask) bool {
+	if g.idle == nil {
+		return false
+	}
+	select {
+	case g.idle <- st:
+		return true
+	default:
+		return false
+	}
+}
+
+// worker runs the subtask, then the ones passed by handOff until it stays idle
+// for longer than configured by WithGoroutineReuse
+func (g *Group) worker(st *subtask) {
+	var timer Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		g.runTask(st.ctx, st.key, st)
+
+		if timer == nil {
+			timer = g.clock.NewTimer(g.reuse)
+		} else {
+			timer.Reset(g.reuse)
+		}
+		select {
+		case st = <-g.idle:
+			if !timer.Stop() {
+				<-timer.C()
+			}
+		case <-timer.C():
+			return
+		}
+	}
+}
+
+// runScheduled runs the subtasks scheduled by launch in the calling goroutine,
+// see WithSynchronous
+func (g *Group) runScheduled() {
+	for {
+		g.mu.Lock()
+		if len(g.inline) == 0 {
+			g.mu.Unlock()
+			return
+		}
+		st := g.inline[0]
+		g.inline = g.inline[1:]
+		g.mu.Unlock()
+
+		g.runTask(st.ctx, st.key, st)
+	}
+}
+
+// taskPath returns the hierarchical name of the subtask with the given name,
+// prefixed by the path of the group, e.g. updater.fetcher
+func (g *Group) taskPath(name string) string {
+	if g.path == "" {
+		return name
+	}
+	return g.path + "." + name
+}
+
+// Go spawns fn as a subtask in Continue mode, the same way as errgroup.Group
+// does. The subtask is named "go". Prefer Spawn in new code, Go exists to ease
+// migration from golang.org/x/sync/errgroup.
+func (g *Group) Go(fn func() error) {
+	g.Spawn("go", Continue, func(ctx context.Context) error {
+		return fn()
+	})
+}
 
-	go g.runTask(logger.WithLogger(g.ctx, log), id, name, onExit, task)
+// Second parameter is the task ID. The only reason to pass it is to add it to
+// the stack trace, so it is kept alive until the end of the function, otherwise
+// the stack trace may show garbage instead.
+//
+// The goroutine is labeled with the names of the subtask and the group, so
+// profiles attribute the work to them.
+func (g *Group) runTask(ctx context.Context, key int64, st *subtask) {
+	var err error
+	pprof.Do(ctx, pprof.Labels("task", st.path, "group", g.path), func(ctx context.Context) {
+		err = g.executeTask(ctx, st)
+	})
+	g.finishTask(st, err)
+	runtime.KeepAlive(key)
 }
 
-// Second parameter is the task ID. It is ignored because the only reason to
-// pass it is to add it to the stack trace
-func (g *Group) runTask(ctx context.Context, _ int64, name string, onExit OnExit, task Task) {
-	err := runTask(ctx, task)
-	logger.Get(ctx).Debug("Task finished", zap.Error(err))
+// executeTask runs the subtask, repeatedly in Restart mode
+func (g *Group) executeTask(ctx context.Context, st *subtask) error {
+	path, onExit := st.path, st.onExit
+
+	// The subtask is shown by go tool trace under its name, with a region for
+	// every run and restart delay
+	if trace.IsEnabled() {
+		var task *trace.Task
+		ctx, task = trace.NewTask(ctx, path)
+		defer task.End()
+	}
+
+	var err error
+	var failures int
+	var recent []time.Time
+	for {
+		g.metrics.taskStarted(path)
+		started := g.clock.Now()
+		runCtx, rerun := context.WithCancelCause(ctx)
+		g.mu.Lock()
+		st.state = TaskRunning
+		st.started = started
+		st.rerun = rerun
+		g.mu.Unlock()
+
+		g.observe(Event{Type: EventTaskStarted, Task: path, ID: st.id, Labels: st.labels})
+
+		stopWatch := g.watchSlow(runCtx, st, started)
+		region := trace.StartRegion(runCtx, "run")
+		err = st.run(runCtx)
+		region.End()
+		stopWatch()
+		rerun(nil)
+		duration := g.clock.Now().Sub(started)
+		g.metrics.taskFinished(path, duration, err)
+		g.observe(Event{Type: EventTaskFinished, Task: path, ID: st.id, Labels: st.labels, Err: err})
+		loggerFromContext(ctx).Debug("Task finished", "error", err, "duration", duration)
+
+		// The result of a run restarted on demand is discarded
+		g.mu.Lock()
+		st.duration = duration
+		restarted := st.reloading && ctx.Err() == nil
+		st.rerun = nil
+		st.reloading = false
+		if st.stopped != nil {
+			close(st.stopped)
+			st.stopped = nil
+		}
+		if restarted {
+			st.restarts++
+		}
+		g.mu.Unlock()
+		if restarted {
+			continue
+		}
+
+		if st.onExitFunc != nil {
+			if onExit, err = g.decide(ctx, st, err); onExit != Restart {
+				return err
+			}
+		}
+		if onExit != Restart || ctx.Err() != nil || IsPermanent(err) || isInternalPanic(err) {
+			return err
+		}
+
+		if err == nil {
+			failures = 0
+		}
+		delay := g.restartBackoff.Delay(failures)
+		state := TaskRestarting
+		if err != nil {
+			failures++
+			if g.breaker.trip(&recent, g.clock.Now()) {
+				failures = 0
+				delay = g.breaker.Cooldown
+				state = TaskCoolingDown
+				loggerFromContext(ctx).Error("Task failed repeatedly, restarts suspended", "error", err, "cooldown", delay)
+			} else {
+				loggerFromContext(ctx).Error("Task failed, restarting", "error", err, "delay", delay)
+			}
+		}
+
+		g.mu.Lock()
+		st.state = state
+		st.restarts++
+		if err != nil {
+			st.lastErr = err
+		}
+		g.mu.Unlock()
+
+		region = trace.StartRegion(ctx, "restart delay")
+		timer := g.clock.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			region.End()
+			return nil
+		case <-timer.C():
+			region.End()
+		}
+	}
+}
+
+// finishTask handles the result of the finished subtask and starts the next
+// queued one, if any
+func (g *Group) finishTask(st *subtask, err error) {
+	if st.onExit == Crash {
+		g.crash(st, err)
+	}
 
 	g.mu.Lock()
-	defer g.mu.Unlock()
+	closing := g.closing
+	done := g.settleTask(st, err)
+	exited := !closing && g.closing
+	g.mu.Unlock()
+
+	// The exit event is emitted before Wait returns
+	if exited {
+		g.observe(Event{Type: EventGroupExited, Err: g.Cause()})
+	}
+	if done != nil {
+		close(done)
+	}
+	if g.synchronous {
+		g.runScheduled()
+	}
+}
 
+// settleTask does the job of finishTask with the mutex of the group held. If
+// the last running subtask has finished, it returns the done channel to close.
+func (g *Group) settleTask(st *subtask, err error) (done chan struct{}) {
+	st.state = TaskFinished
+	st.err = err
 	if err != nil {
-		g.exit(err)
-	} else if !g.closing {
-		switch onExit {
-		case Continue:
+		st.lastErr = err
+	}
+	g.counts.count(err)
+	g.retire(st)
+	st.handle.finish(err)
+	if st.singleton {
+		delete(g.single, st.name)
+	}
+	st.phase.remove()
+	g.notifySettled()
+
+	switch {
+	case st.handle.canceledIndividually(err), st.detached && err == nil:
+		// The group is not affected
+	case err != nil && g.tolerate(st, err):
+		// The error fits in the budget, see WithMaxErrors
+	case err != nil && st.onExit == OnError:
+		if !g.closing || !errors.Is(err, context.Canceled) {
+			g.log.Error("Task failed, error ignored", "task", st.path, "error", err)
+		}
+	case err != nil:
+		g.exit(g.taskError(st, err))
+	case !g.closing:
+		switch st.onExit {
+		case Continue, Restart, OnError:
 		case Exit:
 			g.exit(nil)
-		case Fail:
-			g.exit(errors.Errorf("task %s terminated unexpectedly", name))
+		case Fail, Crash:
+			g.exit(errors.Errorf("task %s terminated unexpectedly", st.path))
 		default:
-			g.exit(errors.Errorf("task %s: %v", name, onExit))
+			g.exit(errors.Errorf("task %s: %v", st.path, st.onExit))
 		}
 	}
 
 	g.running--
 	if g.running == 0 {
-		close(g.done)
+		done = g.done
+		g.done = nil
+	}
+
+	if st.attached {
+		return done
+	}
+	if st.lane != nil {
+		if st.lane.queue.Len() == 0 || g.paused {
+			st.lane.active--
+			return done
+		}
+		g.launch(st.lane.queue.Pop())
+		return done
+	}
+	if g.queue.Len() == 0 || g.paused {
+		g.active--
+		return done
+	}
+
+	// The slot of the finished subtask is passed to the next queued one
+	g.launch(g.queue.Pop())
+	return done
+}
+
+// decide chooses the OnExit mode of the subtask finished with err, see
+// WithOnExitFunc. It returns the error to handle in the chosen mode.
+func (g *Group) decide(ctx context.Context, st *subtask, err error) (OnExit, error) {
+	onExit := st.onExitFunc(st.path, err)
+	if onExit == Restart {
+		return onExit, err
+	}
+
+	g.mu.Lock()
+	st.onExit = onExit
+	if err != nil && (onExit == Continue || onExit == Exit) {
+		st.lastErr = err
+		loggerFromContext(ctx).Error("Task failed, error ignored", "error", err, "onExit", onExit)
+		err = nil
+	}
+	g.mu.Unlock()
+	return onExit, err
+}
+
+// tolerate records err returned by the subtask if it fits in the error budget,
+// the mutex must be held
+func (g *Group) tolerate(st *subtask, err error) bool {
+	if st.onExit != Continue || g.closing || len(g.excused) >= g.maxErrors {
+		return false
 	}
+	g.excused = append(g.excused, errors.WithMessagef(err, "task %s", st.path))
+	g.log.Error("Task failed, error tolerated", "task", st.path, "error", err, "tolerated", len(g.excused), "maxErrors", g.maxErrors)
+	return true
+}
+
+// ToleratedErrors returns the errors of the subtasks tolerated by the group,
+// see WithMaxErrors
+func (g *Group) ToleratedErrors() []error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]error(nil), g.excused...)
+}
+
+// run runs the subtask once, watching its heartbeat if required
+func (st *subtask) run(ctx context.Context) error {
+	if st.prefixOutput {
+		defer st.output.flush()
+	}
+	if st.group == nil || st.group.liveness <= 0 {
+		return st.runTimed(ctx)
+	}
+
+	runCtx, stop := st.watchHeartbeat(ctx)
+	defer stop()
+
+	err := st.runTimed(runCtx)
+	if cause := context.Cause(runCtx); err != nil && ctx.Err() == nil && errors.Is(cause, ErrTaskSilent) {
+		return cause
+	}
+	return err
+}
+
+// runTimed runs the subtask once, limited by its timeout
+func (st *subtask) runTimed(ctx context.Context) error {
+	if st.timeout <= 0 {
+		return runTask(ctx, st.path, st.task)
+	}
+
+	runCtx, cancel := withTimeout(ctx, clockFromContext(ctx), st.timeout)
+	defer cancel()
+
+	err := runTask(runCtx, st.path, st.task)
+	if err != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return errors.Wrapf(err, "task %s timed out after %s", st.path, st.timeout)
+	}
+	return err
 }
 
 func (g *Group) exit(err error) {
@@ -147,12 +902,23 @@ func (g *Group) exit(err error) {
 	if g.closing && errors.Is(err, context.Canceled) {
 		return
 	}
-	if g.err == nil {
+	switch {
+	case g.aggregateErrors && err != nil:
+		g.errs = append(g.errs, err)
+		if len(g.errs) == 1 {
+			g.err = err
+		} else {
+			g.err = stderrors.Join(g.errs...)
+		}
+	case g.err == nil:
 		g.err = err
 	}
 	if !g.closing {
 		g.closing = true
-		g.cancel()
+		if err == nil {
+			err = ErrGroupExited
+		}
+		g.cancel(err)
 	}
 }
 
@@ -164,9 +930,37 @@ func (g *Group) exit(err error) {
 // If the group result is not yet set, Exit sets it to err.
 func (g *Group) Exit(err error) {
 	g.mu.Lock()
-	defer g.mu.Unlock()
-
+	closing := g.closing
 	g.exit(err)
+	g.mu.Unlock()
+
+	if !closing {
+		g.observe(Event{Type: EventGroupExited, Err: g.Cause()})
+	}
+}
+
+// Close stops the group from accepting new subtasks, then waits for the
+// running ones to finish and returns the group result. Unlike Exit, Close
+// doesn't prompt the subtasks to exit.
+//
+// Once the group is closed, SpawnE returns ErrGroupClosed, while Spawn and
+// Attach panic with it.
+func (g *Group) Close() error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+
+	return g.Wait()
+}
+
+// Cause returns the reason the group is shutting down, or nil if it isn't.
+// This is the same as context.Cause called on the group context by subtasks:
+//
+//   - the error which caused the group to exit,
+//   - ErrGroupExited if the group exited without an error,
+//   - the cause of the parent context if that one was canceled.
+func (g *Group) Cause() error {
+	return context.Cause(g.ctx)
 }
 
 // Running returns the number of running subtasks
@@ -183,17 +977,77 @@ func (g *Group) Done() <-chan struct{} {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if g.running == 0 {
+		return closedChan
+	}
+	// The channel is created on demand, so the group doesn't allocate one
+	// every time it gets busy
+	if g.done == nil {
+		g.done = make(chan struct{})
+	}
 	return g.done
 }
 
+// closedChan is returned by Done if no subtasks are running
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
 // Wait blocks until no subtasks are running, then returns the group result.
 //
 // The group result is set by finishing subtasks (see the documentation for
-// OnExit modes) as well as by Exit calls.
+// OnExit modes) as well as by Exit calls and functions registered by Defer.
+//
+// If the group was created with WithRepanic and the result is caused by a
+// panic, Wait panics with the PanicError instead of returning it.
 func (g *Group) Wait() error {
 	<-g.Done()
+	if g.stopReload != nil {
+		g.stopReload()
+	}
+	g.unlist(true)
+	g.runDeferred()
 
-	return g.err
+	g.mu.Lock()
+	err := g.err
+	if g.strict {
+		g.closed = true
+	}
+	g.mu.Unlock()
+
+	if g.repanic {
+		var panicErr PanicError
+		if errors.As(err, &panicErr) {
+			panic(panicErr)
+		}
+	}
+	return err
+}
+
+// WaitCtx waits like Wait does, unless ctx closes first. In that case
+// ctx.Err() is returned and the group is not affected, so WaitCtx or Wait may
+// be called again.
+func (g *Group) WaitCtx(ctx context.Context) error {
+	g.checkWaiter(ctx)
+	select {
+	case <-g.Done():
+		return g.Wait()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryWait returns true and the group result, like Wait does, if no subtasks
+// are running. Otherwise it returns false right away.
+func (g *Group) TryWait() (bool, error) {
+	select {
+	case <-g.Done():
+		return true, g.Wait()
+	default:
+		return false, nil
+	}
 }
 
 // Complete first waits for either the given context to close or the group to
@@ -210,6 +1064,7 @@ func (g *Group) Wait() error {
 //
 //	group.Spawn("subgroup", parallel.Fail, subgroup.Complete)
 func (g *Group) Complete(ctx context.Context) error {
+	g.checkWaiter(ctx)
 	select {
 	case <-ctx.Done():
 	case <-g.ctx.Done():
@@ -0,0 +1,547 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestGroupMaxConcurrent(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(2))
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	var running, maxRunning, finished int32
+	for i := 0; i < 10; i++ {
+		g.Spawn("task", Continue, func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			started <- struct{}{}
+			<-release
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&finished, 1)
+			return nil
+		})
+	}
+	require.Equal(t, 10, g.Running())
+	<-started
+	<-started
+
+	close(release)
+	require.NoError(t, g.Wait())
+	require.EqualValues(t, 2, maxRunning)
+	require.EqualValues(t, 10, finished)
+}
+
+func TestGroupRestart(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithRestartBackoff(Backoff{Initial: time.Millisecond, Max: time.Millisecond}))
+
+	var runs int
+	g.Spawn("restarted", Restart, func(ctx context.Context) error {
+		runs++
+		switch runs {
+		case 1:
+			return nil
+		case 2:
+			return errors.New("oops")
+		default:
+			return Permanent(errors.New("fatal"))
+		}
+	})
+	require.EqualError(t, g.Wait(), "fatal")
+	require.Equal(t, 3, runs)
+}
+
+func TestGroupRestartExit(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithRestartBackoff(Backoff{Initial: time.Hour, Max: time.Hour}))
+
+	started := make(chan struct{})
+	g.Spawn("restarted", Restart, func(ctx context.Context) error {
+		close(started)
+		return errors.New("oops")
+	})
+	<-started
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestGroupRestartModeSubgroup(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithRestartBackoff(Backoff{Initial: time.Millisecond, Max: time.Millisecond}))
+
+	sub := NewSubgroup(g.Spawn, "sub", Restart)
+	sub.Spawn("task", Fail, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.EqualError(t, g.Wait(), "subgroup sub has finished and can't be run again")
+}
+
+func TestGroupErrorAggregation(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithErrorAggregation())
+
+	err1 := errors.New("oops1")
+	err2 := errors.New("oops2")
+	failed := make(chan struct{})
+	g.Spawn("error1", Continue, func(ctx context.Context) error {
+		defer close(failed)
+		return err1
+	})
+	g.Spawn("error2", Continue, func(ctx context.Context) error {
+		<-failed
+		return err2
+	})
+	g.Spawn("canceled", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	require.ErrorIs(t, err, err1)
+	require.ErrorIs(t, err, err2)
+	require.NotErrorIs(t, err, context.Canceled)
+	require.Equal(t, "oops1\noops2", err.Error())
+}
+
+func TestGroupTaskTimeout(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.SpawnWith("fast", Continue, func(ctx context.Context) error {
+		return nil
+	}, WithTaskTimeout(time.Hour))
+	g.SpawnWith("slow", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTaskTimeout(time.Millisecond))
+
+	err := g.Wait()
+	require.EqualError(t, err, "task slow timed out after 1ms: context deadline exceeded")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGroupSpawnRate(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithSpawnRate(rate.Every(10*time.Millisecond), 2))
+
+	started := time.Now()
+	for i := 0; i < 4; i++ {
+		g.Spawn("task", Continue, func(ctx context.Context) error {
+			return nil
+		})
+	}
+	require.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+	require.NoError(t, g.Wait())
+}
+
+func TestGroupPriority(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(1))
+
+	release := make(chan struct{})
+	g.Spawn("blocker", Continue, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	var order []string
+	for _, p := range []struct {
+		name     string
+		priority int
+	}{{"low1", -1}, {"default", 0}, {"high", 10}, {"low2", -1}} {
+		name := p.name
+		g.SpawnWith(name, Continue, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}, WithPriority(p.priority))
+	}
+
+	close(release)
+	require.NoError(t, g.Wait())
+	require.Equal(t, []string{"high", "default", "low1", "low2"}, order)
+}
+
+func TestGroupClose(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	var finished atomic.Bool
+	require.NoError(t, g.SpawnE("task", Continue, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	}))
+
+	require.NoError(t, g.Close())
+	require.True(t, finished.Load())
+	require.NoError(t, g.Context().Err())
+
+	err := g.SpawnE("late", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.ErrorIs(t, err, ErrGroupClosed)
+
+	h := g.SpawnHandle("late", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	<-h.Done()
+	require.ErrorIs(t, h.Err(), ErrGroupClosed)
+
+	requireClosedPanic := func(f func()) {
+		defer func() {
+			err, ok := recover().(error)
+			require.True(t, ok)
+			require.ErrorIs(t, err, ErrGroupClosed)
+		}()
+		f()
+	}
+	requireClosedPanic(func() {
+		g.Spawn("late", Continue, func(ctx context.Context) error {
+			return nil
+		})
+	})
+	requireClosedPanic(func() {
+		NewSubgroup(g.Spawn, "sub", Continue)
+	})
+	requireClosedPanic(func() {
+		g.Attach("late", Continue)
+	})
+	require.Zero(t, g.Running())
+}
+
+func TestGroupWaitCtx(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	release := make(chan struct{})
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		<-release
+		return errors.New("oops")
+	})
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, g.WaitCtx(waitCtx), context.DeadlineExceeded)
+	require.NoError(t, g.Context().Err())
+	require.Equal(t, 1, g.Running())
+
+	close(release)
+	require.EqualError(t, g.WaitCtx(ctx), "oops")
+}
+
+func TestGroupTryWait(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	release := make(chan struct{})
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		<-release
+		return errors.New("oops")
+	})
+
+	finished, err := g.TryWait()
+	require.False(t, finished)
+	require.NoError(t, err)
+
+	close(release)
+	<-g.Done()
+	finished, err = g.TryWait()
+	require.True(t, finished)
+	require.EqualError(t, err, "oops")
+}
+
+func TestGroupCause(t *testing.T) {
+	ctx := context.Background()
+
+	g := NewGroup(ctx)
+	require.NoError(t, g.Cause())
+	var taskCause error
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		taskCause = context.Cause(ctx)
+		return nil
+	})
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.ErrorIs(t, taskCause, ErrGroupExited)
+	require.ErrorIs(t, g.Cause(), ErrGroupExited)
+
+	g = NewGroup(ctx)
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.EqualError(t, g.Wait(), "oops")
+	require.EqualError(t, g.Cause(), "oops")
+
+	parentCtx, cancel := context.WithCancelCause(ctx)
+	g = NewGroup(parentCtx)
+	cause := errors.New("parent")
+	cancel(cause)
+	require.ErrorIs(t, g.Cause(), cause)
+}
+
+func TestGroupProfilerLabels(t *testing.T) {
+	ctx := context.Background()
+	var task, group string
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		sub := NewSubgroup(spawn, "sub", Continue)
+		sub.Spawn("task", Exit, func(ctx context.Context) error {
+			task, _ = pprof.Label(ctx, "task")
+			group, _ = pprof.Label(ctx, "group")
+			return nil
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "sub.task", task)
+	require.Equal(t, "sub", group)
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := FromContext(ctx)
+	require.False(t, ok)
+
+	g := NewGroup(ctx)
+	owner, ok := FromContext(g.Context())
+	require.True(t, ok)
+	require.Same(t, g, owner)
+
+	spawnSibling := func(ctx context.Context) {
+		g, _ := FromContext(ctx)
+		g.Spawn("sibling", Continue, func(ctx context.Context) error {
+			return nil
+		})
+	}
+	var sub *Group
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		spawnSibling(ctx)
+		sub = NewGroup(ctx)
+		return nil
+	})
+	require.NoError(t, g.Wait())
+
+	var names []string
+	for _, info := range g.Tasks() {
+		names = append(names, info.Name)
+	}
+	require.Equal(t, []string{"task", "sibling"}, names)
+	owner, _ = FromContext(sub.Context())
+	require.Same(t, sub, owner)
+}
+
+func TestGroupTrace(t *testing.T) {
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	require.NoError(t, trace.Start(&buf))
+	restarted := make(chan struct{})
+	var runs int
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("traced-task", Restart, func(ctx context.Context) error {
+			runs++
+			if runs == 2 {
+				close(restarted)
+				<-ctx.Done()
+			}
+			return nil
+		})
+		spawn("exit", Exit, func(ctx context.Context) error {
+			<-restarted
+			return nil
+		})
+		return nil
+	}, WithRestartBackoff(Backoff{Initial: time.Millisecond, Max: time.Millisecond}))
+	trace.Stop()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "traced-task")
+	require.Contains(t, buf.String(), "restart delay")
+}
+
+func TestGroupTaskMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(tag string) Middleware {
+		return func(next Task) Task {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				calls = append(calls, tag+":"+TaskName(ctx))
+				mu.Unlock()
+				return next(ctx)
+			}
+		}
+	}
+
+	g := NewGroup(ctx, WithTaskMiddleware(record("outer"), record("inner")))
+	sub := NewSubgroup(g.Spawn, "sub", Continue)
+	sub.Spawn("task", Exit, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+	require.Equal(t, []string{"outer:sub", "inner:sub", "outer:sub.task", "inner:sub.task"}, calls)
+}
+
+func TestGroupMaxErrors(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxErrors(2))
+
+	for i := 1; i <= 2; i++ {
+		i := i
+		g.Spawn("item", Continue, func(ctx context.Context) error {
+			return errors.Errorf("oops%d", i)
+		})
+		_, err := g.WaitN(ctx, i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, g.Context().Err())
+	require.Len(t, g.ToleratedErrors(), 2)
+	require.EqualError(t, g.ToleratedErrors()[1], "task item: oops2")
+
+	g.Spawn("item", Continue, func(ctx context.Context) error {
+		return errors.New("oops3")
+	})
+	require.EqualError(t, g.Wait(), "oops3")
+}
+
+func TestGroupDeterministicSchedule(t *testing.T) {
+	ctx := context.Background()
+
+	run := func(seed int64) []int {
+		g := NewGroup(ctx, WithDeterministicSchedule(seed))
+		g.Pause()
+		var order []int
+		for i := 0; i < 10; i++ {
+			i := i
+			g.Spawn("task", Continue, func(ctx context.Context) error {
+				order = append(order, i)
+				return nil
+			})
+		}
+		g.Resume()
+		require.NoError(t, g.Wait())
+		return order
+	}
+
+	order := run(1)
+	require.Len(t, order, 10)
+	require.Equal(t, order, run(1))
+	require.NotEqual(t, order, run(2))
+}
+
+func TestGroupSynchronous(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithSynchronous(), WithMaxConcurrent(1))
+
+	var order []string
+	g.Spawn("outer", Continue, func(ctx context.Context) error {
+		order = append(order, "outer started")
+		g.Spawn("queued", Continue, func(ctx context.Context) error {
+			order = append(order, "queued")
+			return nil
+		})
+		order = append(order, "outer finished")
+		return nil
+	})
+	order = append(order, "spawned")
+
+	g.Spawn("failed", Continue, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.Error(t, g.Context().Err())
+	require.EqualError(t, g.Wait(), "oops")
+	require.Equal(t, []string{"outer started", "outer finished", "queued", "spawned"}, order)
+}
+
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	return strings.Fields(string(buf))[1]
+}
+
+func TestGroupGoroutineReuse(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithGoroutineReuse(time.Minute))
+
+	ids := make(chan string, 2)
+	for i := 1; i <= 2; i++ {
+		g.Spawn("task", Continue, func(ctx context.Context) error {
+			ids <- goroutineID()
+			return nil
+		})
+		_, err := g.WaitN(ctx, i)
+		require.NoError(t, err)
+		// Let the goroutine become idle
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, g.Wait())
+	require.Equal(t, <-ids, <-ids)
+}
+
+func benchmarkSpawn(b *testing.B, opts ...GroupOption) {
+	g := NewGroup(context.Background(), append([]GroupOption{WithLogger(NopLogger())}, opts...)...)
+	task := func(ctx context.Context) error {
+		return nil
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Spawn("task", Continue, task)
+	}
+	require.NoError(b, g.Wait())
+}
+
+func BenchmarkSpawn(b *testing.B) {
+	benchmarkSpawn(b)
+}
+
+func BenchmarkSpawnGoroutineReuse(b *testing.B) {
+	benchmarkSpawn(b, WithGoroutineReuse(time.Second))
+}
+
+func BenchmarkSpawnSlog(b *testing.B) {
+	benchmarkSpawn(b, WithLogger(SlogLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))))
+}
+
+func TestGroupSpawnMany(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(2))
+
+	names := make(chan string, 3)
+	task := func(ctx context.Context) error {
+		names <- TaskName(ctx)
+		return nil
+	}
+	g.SpawnMany("shard", Continue, []Task{task, task, task})
+	require.NoError(t, g.Wait())
+
+	close(names)
+	var got []string
+	for name := range names {
+		got = append(got, name)
+	}
+	require.ElementsMatch(t, []string{"shard-0", "shard-1", "shard-2"}, got)
+}
@@ -0,0 +1,137 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// TaskHandle controls a single subtask spawned with SpawnHandle
+type TaskHandle struct {
+	canceled atomic.Bool
+	done     chan struct{}
+	err      error
+
+	// The handle is published by SpawnOnce before the subtask is created
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// SpawnHandle spawns a subtask like SpawnWith does, and returns a handle to
+// control it.
+//
+// The handle allows to stop the subtask without shutting down the whole group.
+// If the subtask canceled by the handle returns nil or context.Canceled, the
+// group is not affected by that, regardless of the OnExit mode.
+//
+// If the group has been closed, the returned handle is already done and its
+// Err returns ErrGroupClosed.
+func (g *Group) SpawnHandle(name string, onExit OnExit, task Task, opts ...SpawnOption) *TaskHandle {
+	return g.spawnHandle(newTaskHandle(), name, onExit, task, opts...)
+}
+
+// SpawnOnce spawns a subtask like SpawnHandle does, unless a subtask of the
+// same name spawned by SpawnOnce is still running in the group. In that case
+// the handle of the running subtask is returned and task is discarded.
+//
+// Use it for jobs like cache refreshers, triggered from many places, which
+// need to run at most once at a time.
+func (g *Group) SpawnOnce(name string, onExit OnExit, task Task, opts ...SpawnOption) *TaskHandle {
+	g.mu.Lock()
+	if h, ok := g.single[name]; ok {
+		g.mu.Unlock()
+		return h
+	}
+	h := newTaskHandle()
+	g.single[name] = h
+	g.mu.Unlock()
+
+	h = g.spawnHandle(h, name, onExit, task, append(opts, func(st *subtask) {
+		st.singleton = true
+	})...)
+	select {
+	case <-h.Done():
+		// Spawning failed, the subtask never registered itself
+		g.mu.Lock()
+		if g.single[name] == h {
+			delete(g.single, name)
+		}
+		g.mu.Unlock()
+	default:
+	}
+	return h
+}
+
+func newTaskHandle() *TaskHandle {
+	return &TaskHandle{done: make(chan struct{})}
+}
+
+func (g *Group) spawnHandle(h *TaskHandle, name string, onExit OnExit, task Task, opts ...SpawnOption) *TaskHandle {
+	err := g.SpawnE(name, onExit, task, append(opts, func(st *subtask) {
+		st.handle = h
+	})...)
+	if err != nil {
+		h.setCancel(func() {})
+		h.finish(err)
+	}
+	return h
+}
+
+// Cancel closes the context of the subtask. It does not wait for the subtask
+// to finish, use Done for that.
+func (h *TaskHandle) Cancel() {
+	h.canceled.Store(true)
+
+	h.mu.Lock()
+	cancel := h.cancel
+	h.mu.Unlock()
+
+	// Otherwise the subtask is being spawned and setCancel cancels it
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// setCancel sets the function canceling the subtask, calling it right away if
+// the handle has been canceled before the subtask was created
+func (h *TaskHandle) setCancel(cancel context.CancelFunc) {
+	h.mu.Lock()
+	h.cancel = cancel
+	h.mu.Unlock()
+
+	if h.canceled.Load() {
+		cancel()
+	}
+}
+
+// Done returns a channel that closes when the subtask finishes
+func (h *TaskHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the error returned by the subtask, or nil if the subtask hasn't
+// finished yet
+func (h *TaskHandle) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
+	}
+}
+
+// canceledIndividually reports whether the subtask finished with err because
+// it was canceled by the handle
+func (h *TaskHandle) canceledIndividually(err error) bool {
+	return h != nil && h.canceled.Load() && (err == nil || errors.Is(err, context.Canceled))
+}
+
+func (h *TaskHandle) finish(err error) {
+	if h == nil {
+		return
+	}
+	h.err = err
+	close(h.done)
+}
@@ -0,0 +1,118 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskHandleCancel(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	h := g.SpawnHandle("task", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Spawn("other", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.NoError(t, h.Err())
+	h.Cancel()
+	<-h.Done()
+	require.ErrorIs(t, h.Err(), context.Canceled)
+	require.Equal(t, 1, g.Running())
+	require.NoError(t, g.Context().Err())
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestTaskHandleError(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	h := g.SpawnHandle("task", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("oops")
+	})
+	h.Cancel()
+	<-h.Done()
+	require.EqualError(t, h.Err(), "oops")
+	require.EqualError(t, g.Wait(), "oops")
+}
+
+func TestTaskHandleFinished(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	h := g.SpawnHandle("task", Exit, func(ctx context.Context) error {
+		return nil
+	})
+	<-h.Done()
+	require.NoError(t, h.Err())
+	require.NoError(t, g.Wait())
+	require.Error(t, g.Context().Err())
+}
+
+func TestSpawnOnce(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	release := make(chan struct{})
+	var runs int32
+	refresh := func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return nil
+	}
+
+	h1 := g.SpawnOnce("refresh", Continue, refresh)
+	h2 := g.SpawnOnce("refresh", Continue, refresh)
+	require.Same(t, h1, h2)
+
+	close(release)
+	<-h1.Done()
+	h3 := g.SpawnOnce("refresh", Continue, refresh)
+	require.NotSame(t, h1, h3)
+	<-h3.Done()
+
+	require.NoError(t, g.Wait())
+	require.EqualValues(t, 2, runs)
+}
+
+func TestSpawnOnceCancelWhileSpawning(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithSpawnRate(10, 1))
+
+	// The burst is used up, so the next subtask waits for the limiter
+	g.Spawn("first", Continue, func(ctx context.Context) error {
+		return nil
+	})
+
+	spawned := make(chan *TaskHandle)
+	go func() {
+		spawned <- g.SpawnOnce("refresh", Continue, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	require.Eventually(t, func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return g.single["refresh"] != nil
+	}, time.Second, time.Millisecond)
+
+	h := g.SpawnOnce("refresh", Continue, nil)
+	h.Cancel()
+	require.Same(t, h, <-spawned)
+	<-h.Done()
+	require.ErrorIs(t, h.Err(), context.Canceled)
+	require.NoError(t, g.Wait())
+}
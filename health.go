@@ -0,0 +1,159 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// HealthStatus is an enumeration of subtask health states reported by
+// Group.Health
+type HealthStatus int
+
+const (
+	// HealthRunning means the subtask is running or queued
+	HealthRunning HealthStatus = iota
+
+	// HealthRestarting means the subtask in Restart mode has failed and waits
+	// to be started again
+	HealthRestarting
+
+	// HealthFinished means the subtask has finished successfully, or has been
+	// canceled
+	HealthFinished
+
+	// HealthFailed means the subtask has finished with an error
+	HealthFailed
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthRunning:
+		return "running"
+	case HealthRestarting:
+		return "restarting"
+	case HealthFinished:
+		return "finished"
+	case HealthFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("invalid HealthStatus: %d", s)
+	}
+}
+
+// TaskHealth describes the health of a subtask
+type TaskHealth struct {
+	// Path is the hierarchical name of the subtask, see TaskInfo.Path
+	Path string
+
+	// Status is the health state of the subtask
+	Status HealthStatus
+
+	// Restarts is the number of times the subtask has been restarted
+	Restarts int
+
+	// LastErr is the last error returned by any run of the subtask
+	LastErr error
+
+	// Subtasks describes the subtasks of the groups created by the subtask
+	Subtasks []TaskHealth
+}
+
+// Health describes the health of a group
+type Health struct {
+	// Healthy is false if any subtask, including the subtasks of subgroups, is
+	// failed or restarting
+	Healthy bool
+
+	// Tasks describes the subtasks of the group, see Group.Tasks for the
+	// subtasks included
+	Tasks []TaskHealth
+}
+
+// Health returns the health of the group and its subgroups
+func (g *Group) Health() Health {
+	tasks, healthy := taskHealth(g.Tasks())
+	return Health{Healthy: healthy, Tasks: tasks}
+}
+
+func taskHealth(infos []TaskInfo) ([]TaskHealth, bool) {
+	healthy := true
+	tasks := make([]TaskHealth, 0, len(infos))
+	for _, info := range infos {
+		task := TaskHealth{
+			Path:     info.Path,
+			Restarts: info.Restarts,
+			LastErr:  info.LastErr,
+		}
+		switch {
+		case info.State == TaskRestarting, info.State == TaskCoolingDown:
+			task.Status = HealthRestarting
+		case info.State != TaskFinished:
+			task.Status = HealthRunning
+		case info.Err == nil || errors.Is(info.Err, context.Canceled):
+			task.Status = HealthFinished
+		default:
+			task.Status = HealthFailed
+		}
+		if task.Status == HealthRestarting || task.Status == HealthFailed {
+			healthy = false
+		}
+		for _, subgroup := range info.Subgroups {
+			subtasks, subHealthy := taskHealth(subgroup)
+			task.Subtasks = append(task.Subtasks, subtasks...)
+			healthy = healthy && subHealthy
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, healthy
+}
+
+// HealthHandler returns an HTTP handler reporting the health of the group as
+// JSON, suitable for /healthz endpoints. The status code is 200 if the group
+// is healthy and 503 otherwise.
+func HealthHandler(g *Group) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := g.Health()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !health.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(healthJSON{Healthy: health.Healthy, Tasks: healthTasks(health.Tasks)})
+	})
+}
+
+type healthJSON struct {
+	Healthy bool             `json:"healthy"`
+	Tasks   []healthTaskJSON `json:"tasks"`
+}
+
+type healthTaskJSON struct {
+	Path     string           `json:"path"`
+	Status   string           `json:"status"`
+	Restarts int              `json:"restarts,omitempty"`
+	LastErr  string           `json:"lastError,omitempty"`
+	Subtasks []healthTaskJSON `json:"subtasks,omitempty"`
+}
+
+func healthTasks(tasks []TaskHealth) []healthTaskJSON {
+	result := make([]healthTaskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		t := healthTaskJSON{
+			Path:     task.Path,
+			Status:   task.Status.String(),
+			Restarts: task.Restarts,
+			Subtasks: healthTasks(task.Subtasks),
+		}
+		if task.LastErr != nil {
+			t.LastErr = task.LastErr.Error()
+		}
+		result = append(result, t)
+	}
+	return result
+}
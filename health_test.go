@@ -0,0 +1,59 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithRestartBackoff(Backoff{Initial: time.Hour, Max: time.Hour}))
+
+	sub := NewSubgroup(g.Spawn, "sub", Continue)
+	started := make(chan struct{})
+	sub.Spawn("worker", Continue, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	health := g.Health()
+	require.True(t, health.Healthy)
+	require.Len(t, health.Tasks, 1)
+	require.Equal(t, HealthRunning, health.Tasks[0].Status)
+	require.Len(t, health.Tasks[0].Subtasks, 1)
+	require.Equal(t, "sub.worker", health.Tasks[0].Subtasks[0].Path)
+
+	sub.Spawn("flaky", Restart, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.Eventually(t, func() bool {
+		return !g.Health().Healthy
+	}, time.Second, time.Millisecond)
+
+	flaky := g.Health().Tasks[0].Subtasks[1]
+	require.Equal(t, "sub.flaky", flaky.Path)
+	require.Equal(t, HealthRestarting, flaky.Status)
+	require.Equal(t, 1, flaky.Restarts)
+	require.EqualError(t, flaky.LastErr, "oops")
+
+	rec := httptest.NewRecorder()
+	HealthHandler(g).ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var body healthJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.False(t, body.Healthy)
+	require.Equal(t, "restarting", body.Tasks[0].Subtasks[1].Status)
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.True(t, g.Health().Healthy)
+}
@@ -0,0 +1,60 @@
+package parallel
+
+import "context"
+
+// WithLabels attaches the key/value labels to the subtask, e.g. the tenant or
+// the shard it works for. The labels are reported by Group.Tasks and in
+// events, and the subtasks may be selected by them, see
+// Group.TasksWithLabels. Labels given by several options are merged.
+func WithLabels(labels map[string]string) SpawnOption {
+	return func(st *subtask) {
+		merged := make(map[string]string, len(st.labels)+len(labels))
+		for k, v := range st.labels {
+			merged[k] = v
+		}
+		for k, v := range labels {
+			merged[k] = v
+		}
+		st.labels = merged
+	}
+}
+
+// TaskLabels returns the labels of the subtask owning ctx, see WithLabels.
+// The returned map must not be modified.
+func TaskLabels(ctx context.Context) map[string]string {
+	if st, ok := ctx.Value(subtaskKey).(*subtask); ok {
+		return st.labels
+	}
+	return nil
+}
+
+// HasLabels reports whether the subtask has all the labels of the selector
+// with the same values
+func (info TaskInfo) HasLabels(selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := info.Labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// TasksWithLabels returns the subtasks reported by Tasks, including the ones
+// of subgroups, which have the labels of the selector, see TaskInfo.HasLabels.
+// The subtasks are returned in a flat list, in the order of Tasks, each with
+// all its subgroups.
+func (g *Group) TasksWithLabels(selector map[string]string) []TaskInfo {
+	return selectTasks(nil, g.Tasks(), selector)
+}
+
+func selectTasks(selected, infos []TaskInfo, selector map[string]string) []TaskInfo {
+	for _, info := range infos {
+		if info.HasLabels(selector) {
+			selected = append(selected, info)
+		}
+		for _, subgroup := range info.Subgroups {
+			selected = selectTasks(selected, subgroup, selector)
+		}
+	}
+	return selected
+}
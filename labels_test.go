@@ -0,0 +1,63 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLabels(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	spawned := map[string]map[string]string{}
+	g := NewGroup(ctx, WithObserver(func(event Event) {
+		if event.Type == EventTaskSpawned {
+			mu.Lock()
+			defer mu.Unlock()
+			spawned[event.Task] = event.Labels
+		}
+	}))
+
+	labels := make(chan map[string]string, 1)
+	release := make(chan struct{})
+	task := func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+	g.SpawnWith("a", Continue, func(ctx context.Context) error {
+		labels <- TaskLabels(ctx)
+		sub := NewGroup(ctx)
+		sub.SpawnWith("b", Continue, task, WithLabels(map[string]string{"tenant": "acme", "shard": "2"}))
+		sub.SpawnWith("c", Continue, task, WithLabels(map[string]string{"tenant": "other"}))
+		return sub.Wait()
+	}, WithLabels(map[string]string{"tenant": "acme"}), WithLabels(map[string]string{"component": "api"}))
+	g.Spawn("d", Continue, task)
+
+	require.Equal(t, map[string]string{"tenant": "acme", "component": "api"}, <-labels)
+	require.Eventually(t, func() bool {
+		return len(g.TasksWithLabels(nil)) == 4
+	}, time.Second, time.Millisecond)
+
+	var paths []string
+	for _, info := range g.TasksWithLabels(map[string]string{"tenant": "acme"}) {
+		paths = append(paths, info.Path)
+	}
+	require.Equal(t, []string{"a", "a.b"}, paths)
+
+	selected := g.TasksWithLabels(map[string]string{"tenant": "acme", "shard": "2"})
+	require.Len(t, selected, 1)
+	require.Equal(t, "a.b", selected[0].Path)
+	require.Empty(t, g.TasksWithLabels(map[string]string{"tenant": "none"}))
+
+	close(release)
+	require.NoError(t, g.Wait())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, map[string]string{"tenant": "other"}, spawned["a.c"])
+	require.Nil(t, spawned["d"])
+}
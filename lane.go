@@ -0,0 +1,41 @@
+package parallel
+
+// WithLane defines a named lane of the group allowing at most limit subtasks
+// assigned to it by InLane to run at the same time, e.g.
+// WithLane("cpu", runtime.NumCPU()) and WithLane("io", 256). The rest of the
+// subtasks of the lane are queued in order of priority.
+//
+// Lanes are limited independently of each other and of WithMaxConcurrent,
+// which only limits the subtasks not assigned to any lane, so CPU-heavy
+// subtasks can't starve IO-bound ones and vice versa. A non-positive limit
+// means the lane is unlimited.
+func WithLane(name string, limit int) GroupOption {
+	return func(g *Group) {
+		if g.lanes == nil {
+			g.lanes = map[string]*lane{}
+		}
+		g.lanes[name] = &lane{limit: limit}
+	}
+}
+
+// InLane assigns the subtask to the named lane of the group, see WithLane.
+// Spawning a subtask into a lane the group doesn't define fails.
+func InLane(name string) SpawnOption {
+	return func(st *subtask) {
+		st.laneName = name
+	}
+}
+
+type lane struct {
+	limit int
+
+	// Protected by the mutex of the group
+	active int
+	queue  priorityQueue[*subtask]
+}
+
+// full reports whether another subtask of the lane can't be started right
+// away. The mutex must be held.
+func (l *lane) full() bool {
+	return l.limit > 0 && l.active >= l.limit
+}
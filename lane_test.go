@@ -0,0 +1,65 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLanes(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithLane("cpu", 1), WithLane("io", 3), WithMaxConcurrent(1))
+
+	var cpu, io, other atomic.Int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	task := func(running *atomic.Int32) Task {
+		return func(ctx context.Context) error {
+			running.Add(1)
+			started <- struct{}{}
+			<-release
+			running.Add(-1)
+			return nil
+		}
+	}
+	for i := 0; i < 3; i++ {
+		g.SpawnWith("cpu", Continue, task(&cpu), InLane("cpu"))
+		g.SpawnWith("io", Continue, task(&io), InLane("io"))
+		g.Spawn("other", Continue, task(&other))
+	}
+
+	// One subtask of the cpu lane, all of the io lane and one of the rest run
+	for i := 0; i < 5; i++ {
+		<-started
+	}
+	require.EqualValues(t, 1, cpu.Load())
+	require.EqualValues(t, 3, io.Load())
+	require.EqualValues(t, 1, other.Load())
+
+	err := g.SpawnE("gpu", Continue, task(&other), InLane("gpu"))
+	require.Error(t, err)
+
+	close(release)
+	require.NoError(t, g.Wait())
+}
+
+func TestGroupLanesPaused(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithLane("io", 2))
+
+	g.Pause()
+	var runs atomic.Int32
+	for i := 0; i < 4; i++ {
+		g.SpawnWith("io", Continue, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}, InLane("io"))
+	}
+	require.EqualValues(t, 0, runs.Load())
+
+	g.Resume()
+	require.NoError(t, g.Wait())
+	require.EqualValues(t, 4, runs.Load())
+}
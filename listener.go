@@ -0,0 +1,61 @@
+package parallel
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// ServeListener accepts connections from the listener and handles each of them
+// in a subtask of a subgroup, until ctx closes. At most maxConns connections
+// are handled at a time, a non-positive maxConns means no limit.
+//
+// Errors returned by handle are logged, they don't stop the server. When ctx
+// closes, the listener is closed and ServeListener waits for the active
+// connections to be handled, so handle should return once its ctx closes. The
+// connection is closed after handle returns.
+func ServeListener(ctx context.Context, lis net.Listener, maxConns int, handle func(ctx context.Context, conn net.Conn) error) error {
+	return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		conns := NewSubgroup(spawn, "conns", Continue)
+
+		var slots chan struct{}
+		if maxConns > 0 {
+			slots = make(chan struct{}, maxConns)
+		}
+		spawn("accept", Fail, func(ctx context.Context) error {
+			for {
+				if slots != nil {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case slots <- struct{}{}:
+					}
+				}
+
+				conn, err := lis.Accept()
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					return errors.WithStack(err)
+				}
+				conns.Spawn("conn", OnError, func(ctx context.Context) error {
+					defer func() {
+						_ = conn.Close()
+						if slots != nil {
+							<-slots
+						}
+					}()
+					return handle(ctx, conn)
+				})
+			}
+		})
+		spawn("closer", Fail, func(ctx context.Context) error {
+			<-ctx.Done()
+			_ = lis.Close()
+			return ctx.Err()
+		})
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeListener(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- ServeListener(ctx, lis, 2, func(ctx context.Context, conn net.Conn) error {
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			_, err = conn.Write([]byte(line))
+			return errors.WithStack(err)
+		})
+	}()
+
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", lis.Addr().String())
+		require.NoError(t, err)
+		_, err = conn.Write([]byte("hello\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", line)
+		require.NoError(t, conn.Close())
+	}
+
+	cancel()
+	require.ErrorIs(t, <-result, context.Canceled)
+}
@@ -0,0 +1,102 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTaskSilent is the cause of closing the context of a subtask which has
+// stopped calling Heartbeat, see WithLiveness
+var ErrTaskSilent = errors.New("heartbeat missed")
+
+// WithLiveness makes the group watch the subtasks calling Heartbeat.
+//
+// Watching a subtask starts with its first heartbeat. If the subtask doesn't
+// call Heartbeat again within maxSilence, it is logged with its stack trace
+// and reported as EventTaskSilent, and its context is closed with
+// ErrTaskSilent as the cause. If the subtask then returns an error, the error
+// is replaced by one wrapping ErrTaskSilent, so it is handled as a failure,
+// and restarted in Restart mode.
+func WithLiveness(maxSilence time.Duration) GroupOption {
+	return func(g *Group) {
+		g.liveness = maxSilence
+	}
+}
+
+// Heartbeat reports that the subtask running with ctx is alive, see
+// WithLiveness. It does nothing if the group of the subtask doesn't watch
+// liveness.
+func Heartbeat(ctx context.Context) {
+	st, ok := ctx.Value(subtaskKey).(*subtask)
+	if !ok {
+		return
+	}
+	if hb := st.beat.Load(); hb != nil {
+		hb.beat()
+	}
+}
+
+// heartbeat watches a single run of the subtask
+type heartbeat struct {
+	st     *subtask
+	cancel context.CancelCauseFunc
+
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+// watchHeartbeat returns the context for a run of the subtask, closed when
+// the subtask misses a heartbeat, and a function to stop watching it
+func (st *subtask) watchHeartbeat(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	hb := &heartbeat{st: st, cancel: cancel}
+	st.beat.Store(hb)
+	return ctx, func() {
+		st.beat.CompareAndSwap(hb, nil)
+		hb.stop()
+		cancel(nil)
+	}
+}
+
+func (hb *heartbeat) beat() {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	switch {
+	case hb.stopped:
+	case hb.timer == nil:
+		hb.timer = hb.st.group.clock.AfterFunc(hb.st.group.liveness, hb.miss)
+	default:
+		hb.timer.Reset(hb.st.group.liveness)
+	}
+}
+
+func (hb *heartbeat) stop() {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+
+	hb.stopped = true
+	if hb.timer != nil {
+		hb.timer.Stop()
+	}
+}
+
+func (hb *heartbeat) miss() {
+	hb.mu.Lock()
+	if hb.stopped {
+		hb.mu.Unlock()
+		return
+	}
+	hb.stopped = true
+	hb.mu.Unlock()
+
+	st, g := hb.st, hb.st.group
+	stack := taskStacks(map[int64]bool{st.key: true})[st.key]
+	g.log.Error("Task missed heartbeat", "task", st.path, "id", hexID(st.id), "maxSilence", g.liveness, "stack", stack)
+	g.observe(Event{Type: EventTaskSilent, Task: st.path, ID: st.id, Labels: st.labels, Stack: stack})
+	hb.cancel(errors.WithMessagef(ErrTaskSilent, "task %s silent for more than %s", st.path, g.liveness))
+}
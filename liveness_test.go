@@ -0,0 +1,57 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveness(t *testing.T) {
+	ctx := context.Background()
+
+	silent := make(chan Event, 1)
+	g := NewGroup(ctx, WithLiveness(20*time.Millisecond), WithObserver(func(event Event) {
+		if event.Type == EventTaskSilent {
+			silent <- event
+		}
+	}))
+	g.Spawn("quiet", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	g.Spawn("beating", Continue, func(ctx context.Context) error {
+		for i := 0; i < 10; i++ {
+			Heartbeat(ctx)
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	})
+	g.Spawn("wedged", Continue, func(ctx context.Context) error {
+		Heartbeat(ctx)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	require.ErrorIs(t, err, ErrTaskSilent)
+	require.EqualError(t, err, "task wedged silent for more than 20ms: heartbeat missed")
+
+	event := <-silent
+	require.Equal(t, "wedged", event.Task)
+	require.NotEmpty(t, event.Stack)
+	require.Empty(t, silent)
+}
+
+func TestHeartbeatWithoutLiveness(t *testing.T) {
+	ctx := context.Background()
+
+	Heartbeat(ctx)
+	require.NoError(t, Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("task", Exit, func(ctx context.Context) error {
+			Heartbeat(ctx)
+			return nil
+		})
+		return nil
+	}))
+}
@@ -0,0 +1,275 @@
+package parallel
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+)
+
+// Logger is the logging backend used by groups to report task lifecycle
+// events.
+//
+// Arguments following the message are alternating keys and values, the same
+// way as in slog.Logger. Backend-specific fields, like zap.Field, may be passed
+// as well if the backend supports them.
+//
+// A Logger may also implement DebugEnabled() bool, reporting false if debug
+// entries are discarded, so groups don't spend time building them.
+type Logger interface {
+	// Named returns a logger for the subtask with the given name
+	Named(name string) Logger
+
+	// With returns a logger adding the given fields to every entry
+	With(args ...interface{}) Logger
+
+	// Debug logs a debug message
+	Debug(msg string, args ...interface{})
+
+	// Error logs an error message
+	Error(msg string, args ...interface{})
+}
+
+// ContextInjector may be implemented by a Logger which also stores itself in
+// the task context in a backend-specific way, so the task is able to retrieve
+// it without knowing about this package
+type ContextInjector interface {
+	// Inject returns a context carrying the logger
+	Inject(ctx context.Context) context.Context
+}
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	slogKey
+	taskIDKey
+	onPanicKey
+	stackCaptureKey
+	subtaskKey
+	observerKey
+	middlewareKey
+	clockKey
+	outputKey
+	groupKey
+)
+
+var (
+	defaultLoggerMu sync.RWMutex
+	defaultLogger   Logger
+)
+
+// SetDefaultLogger sets the logger used by groups created without WithLogger
+// in a context which does not carry a logger of a parent group.
+//
+// If the default logger is not set, slog.Default() is used through SlogLogger.
+// Package github.com/outofforest/parallel/zaplogger provides an adapter for
+// zap.
+func SetDefaultLogger(l Logger) {
+	defaultLoggerMu.Lock()
+	defer defaultLoggerMu.Unlock()
+
+	defaultLogger = l
+}
+
+// WithLogger configures the logger used by the group and its subgroups
+func WithLogger(l Logger) GroupOption {
+	return func(g *Group) {
+		g.log = l
+	}
+}
+
+// WithName names the group. The name becomes a part of the hierarchical names
+// of its subtasks, e.g. scheduler.worker, which are reported in logs and
+// errors, and of the name of its logger. Names of nested groups compose, the
+// same way as names of the subtasks owning them do.
+func WithName(name string) GroupOption {
+	return func(g *Group) {
+		g.name = name
+	}
+}
+
+// WithFields makes the group add the fields to every entry logged by it, its
+// subtasks and its subgroups. The fields are passed to Logger.With, so they
+// are alternating keys and values, or backend-specific fields like zap.Field.
+// Fields of nested groups compose.
+func WithFields(fields ...interface{}) GroupOption {
+	return func(g *Group) {
+		g.fields = append(g.fields, fields...)
+	}
+}
+
+// loggerFromContext returns the logger of the task owning ctx, falling back to
+// the default one
+func loggerFromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerKey).(Logger); ok {
+		return l
+	}
+
+	defaultLoggerMu.RLock()
+	l := defaultLogger
+	defaultLoggerMu.RUnlock()
+
+	if l != nil {
+		return l
+	}
+	return SlogLogger(slog.Default())
+}
+
+// withLogger returns a context carrying the given logger
+func withLogger(ctx context.Context, l Logger) context.Context {
+	if ci, ok := l.(ContextInjector); ok {
+		ctx = ci.Inject(ctx)
+	}
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// NopLogger returns a Logger discarding everything
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Named(string) Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) With(...interface{}) Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+
+func (nopLogger) Error(string, ...interface{}) {}
+
+func (nopLogger) DebugEnabled() bool {
+	return false
+}
+
+// SlogLogger returns a Logger writing to the given slog logger.
+//
+// Names of tasks are added to log entries as the "logger" attribute. Tasks of a
+// group using this logger may retrieve the slog logger annotated with their name
+// and ID using Slog.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{base: l}
+}
+
+// Slog returns the slog logger of the task owning ctx, annotated with the task
+// name and ID. If the group running the task does not use SlogLogger,
+// slog.Default() is returned.
+func Slog(ctx context.Context) *slog.Logger {
+	if t, ok := ctx.Value(slogKey).(*slogTask); ok {
+		return t.logger()
+	}
+	return slog.Default()
+}
+
+// slogLogger annotates entries with the name of the task only when something
+// is logged, as most subtasks never log anything
+type slogLogger struct {
+	base  *slog.Logger
+	name  string
+	named *slogNamed
+}
+
+// slogNamed builds the slog logger of a named task on first use
+type slogNamed struct {
+	once sync.Once
+	base *slog.Logger
+	name string
+	args []interface{}
+	l    *slog.Logger
+}
+
+func (n *slogNamed) logger() *slog.Logger {
+	n.once.Do(func() {
+		n.l = n.base.With("logger", n.name)
+		if len(n.args) > 0 {
+			n.l = n.l.With(n.args...)
+		}
+	})
+	return n.l
+}
+
+// slogTask builds the slog logger returned by Slog on first use
+type slogTask struct {
+	once sync.Once
+	log  slogLogger
+	id   int64
+	l    *slog.Logger
+}
+
+func (t *slogTask) logger() *slog.Logger {
+	t.once.Do(func() {
+		t.l = t.log.logger()
+		if t.id != 0 {
+			t.l = t.l.With("id", hexID(t.id))
+		}
+	})
+	return t.l
+}
+
+func (s slogLogger) logger() *slog.Logger {
+	if s.named == nil {
+		return s.base
+	}
+	return s.named.logger()
+}
+
+func (s slogLogger) Named(name string) Logger {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	return slogLogger{base: s.base, name: name, named: &slogNamed{base: s.base, name: name}}
+}
+
+func (s slogLogger) With(args ...interface{}) Logger {
+	l := slogLogger{base: s.base.With(args...), name: s.name}
+	if s.named != nil {
+		l.named = &slogNamed{base: s.named.base, name: s.name, args: append(append([]interface{}(nil), s.named.args...), args...)}
+	}
+	return l
+}
+
+func (s slogLogger) Debug(msg string, args ...interface{}) {
+	s.logger().Debug(msg, args...)
+}
+
+func (s slogLogger) Error(msg string, args ...interface{}) {
+	s.logger().Error(msg, args...)
+}
+
+func (s slogLogger) DebugEnabled() bool {
+	return s.base.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (s slogLogger) Inject(ctx context.Context) context.Context {
+	id, _ := ctx.Value(taskIDKey).(int64)
+	return context.WithValue(ctx, slogKey, &slogTask{log: s, id: id})
+}
+
+// debugEnabled tells if debug entries written to the logger are not discarded.
+// Loggers may report it by implementing DebugEnabled() bool, so groups skip
+// building the entries.
+func debugEnabled(l Logger) bool {
+	if e, ok := l.(interface{ DebugEnabled() bool }); ok {
+		return e.DebugEnabled()
+	}
+	return true
+}
+
+// hexID formats a task ID in log entries the same way as fmt.Sprintf("%x", id)
+// does, but only if the entry is written
+type hexID int64
+
+func (id hexID) String() string {
+	return strconv.FormatInt(int64(id), 16)
+}
+
+// MarshalText implements encoding.TextMarshaler, used by slog and JSON
+// encoders
+func (id hexID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
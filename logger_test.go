@@ -0,0 +1,146 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogger struct {
+	name string
+
+	mu      *sync.Mutex
+	entries *[]string
+}
+
+func newRecordingLogger() recordingLogger {
+	return recordingLogger{mu: &sync.Mutex{}, entries: &[]string{}}
+}
+
+func (l recordingLogger) Named(name string) Logger {
+	l.name = strings.TrimPrefix(l.name+"."+name, ".")
+	return l
+}
+
+func (l recordingLogger) With(...interface{}) Logger {
+	return l
+}
+
+func (l recordingLogger) Debug(msg string, _ ...interface{}) {
+	l.log(msg)
+}
+
+func (l recordingLogger) Error(msg string, _ ...interface{}) {
+	l.log(msg)
+}
+
+func (l recordingLogger) log(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	*l.entries = append(*l.entries, l.name+": "+msg)
+}
+
+func TestWithLogger(t *testing.T) {
+	log := newRecordingLogger()
+	err := Run(context.Background(), func(ctx context.Context, spawn SpawnFn) error {
+		sub := NewSubgroup(spawn, "sub", Continue)
+		sub.Spawn("task", Exit, func(ctx context.Context) error {
+			return nil
+		})
+		return nil
+	}, WithLogger(log))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		"sub: Task spawned",
+		"sub.task: Task spawned",
+		"sub.task: Task finished",
+		"sub: Task finished",
+	}, *log.entries)
+}
+
+func TestNopLogger(t *testing.T) {
+	g := NewGroup(context.Background(), WithLogger(NopLogger()))
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		panic("oops")
+	})
+	require.Error(t, g.Wait())
+}
+
+func TestDefaultLogger(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+}
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	err := Run(context.Background(), func(ctx context.Context, spawn SpawnFn) error {
+		sub := NewSubgroup(spawn, "sub", Continue, "shard", "a")
+		sub.Spawn("task", Exit, func(ctx context.Context) error {
+			Slog(ctx).Info("Hello")
+			return nil
+		})
+		return nil
+	}, WithLogger(SlogLogger(l)))
+	require.NoError(t, err)
+
+	var hello map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "Hello" {
+			hello = entry
+		}
+	}
+	require.NotNil(t, hello)
+	require.Equal(t, "sub.task", hello["logger"])
+	require.Equal(t, "a", hello["shard"])
+	require.NotEmpty(t, hello["id"])
+}
+
+func TestGroupNameAndFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	g := NewGroup(context.Background(), WithLogger(SlogLogger(l)), WithName("scheduler"), WithFields("region", "eu"))
+	g.Spawn("shard", Continue, func(ctx context.Context) error {
+		sub := NewGroup(ctx, WithName("workers"), WithFields("shard", "7"), WithTaskErrors())
+		sub.Spawn("worker", Continue, func(ctx context.Context) error {
+			Slog(ctx).Info("Hello", "task", TaskName(ctx))
+			return errors.New("oops")
+		})
+		return sub.Wait()
+	})
+	var taskErr TaskError
+	require.ErrorAs(t, g.Wait(), &taskErr)
+	require.Equal(t, "scheduler.shard.workers.worker", taskErr.Name)
+
+	var hello map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "Hello" {
+			hello = entry
+		}
+	}
+	require.NotNil(t, hello)
+	require.Equal(t, "scheduler.shard.workers.worker", hello["logger"])
+	require.Equal(t, "scheduler.shard.workers.worker", hello["task"])
+	require.Equal(t, "eu", hello["region"])
+	require.Equal(t, "7", hello["shard"])
+}
+
+func TestSlogDefault(t *testing.T) {
+	require.Equal(t, slog.Default(), Slog(context.Background()))
+}
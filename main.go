@@ -0,0 +1,58 @@
+package parallel
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Exit codes returned by ExitCode
+const (
+	// ExitCodeSuccess means the task has succeeded, or has been shut down by a
+	// signal
+	ExitCodeSuccess = 0
+
+	// ExitCodeFailure means the task has failed
+	ExitCodeFailure = 1
+
+	// ExitCodePanic means a subtask has panicked. It matches the exit code of
+	// a Go program terminated by an unrecovered panic.
+	ExitCodePanic = 2
+)
+
+// ExitCode maps the result of a task to the exit code of the process, see
+// Main. Nil and context.Canceled, which is returned by subtasks shut down
+// because of a signal, mean success.
+func ExitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return ExitCodeSuccess
+	case errors.As(err, new(PanicError)):
+		return ExitCodePanic
+	default:
+		return ExitCodeFailure
+	}
+}
+
+// Main runs the task like RunWithSignals does, handling SIGINT and SIGTERM,
+// and terminates the process with the exit code corresponding to the result,
+// see ExitCode. The error, if any, is logged before exiting.
+//
+// Example:
+//
+//	func main() {
+//	    parallel.Main(func(ctx context.Context, spawn parallel.SpawnFn) error {
+//	        spawn("server", parallel.Fail, server.Run)
+//	        return nil
+//	    })
+//	}
+func Main(start func(ctx context.Context, spawn SpawnFn) error) {
+	ctx := context.Background()
+	err := RunWithSignals(ctx, start)
+	code := ExitCode(err)
+	if code != ExitCodeSuccess {
+		loggerFromContext(ctx).Error("Application failed", "error", err, "exitCode", code)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,25 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	ctx := context.Background()
+
+	require.Equal(t, ExitCodeSuccess, ExitCode(nil))
+	require.Equal(t, ExitCodeSuccess, ExitCode(errors.WithStack(context.Canceled)))
+	require.Equal(t, ExitCodeFailure, ExitCode(errors.New("oops")))
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("panicking", Fail, func(ctx context.Context) error {
+			panic("oops")
+		})
+		return nil
+	})
+	require.Equal(t, ExitCodePanic, ExitCode(err))
+}
@@ -0,0 +1,113 @@
+package parallel
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WithMetrics makes the group report task lifecycle metrics to reg. All the
+// metrics are labeled by task name:
+//
+//   - parallel_tasks_spawned_total: number of spawned tasks
+//   - parallel_tasks_running: number of tasks currently running
+//   - parallel_tasks_finished_total: number of finished tasks
+//   - parallel_tasks_failed_total: number of tasks which returned an error
+//   - parallel_tasks_panicked_total: number of tasks which panicked
+//   - parallel_task_duration_seconds: histogram of task run durations
+//
+// The same registerer may be passed to many groups, the metrics are shared
+// between them then.
+func WithMetrics(reg prometheus.Registerer) GroupOption {
+	m := newMetrics(reg)
+	return func(g *Group) {
+		g.metrics = m
+	}
+}
+
+// metrics collects task lifecycle metrics. All the methods are no-op on nil
+// receiver so the group does not need to check if metrics are enabled.
+type metrics struct {
+	spawned  *prometheus.CounterVec
+	running  *prometheus.GaugeVec
+	finished *prometheus.CounterVec
+	failed   *prometheus.CounterVec
+	panicked *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	labels := []string{"task"}
+	return &metrics{
+		spawned: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parallel_tasks_spawned_total",
+			Help: "Number of spawned tasks",
+		}, labels)),
+		running: register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "parallel_tasks_running",
+			Help: "Number of tasks currently running",
+		}, labels)),
+		finished: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parallel_tasks_finished_total",
+			Help: "Number of finished tasks",
+		}, labels)),
+		failed: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parallel_tasks_failed_total",
+			Help: "Number of tasks which returned an error",
+		}, labels)),
+		panicked: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "parallel_tasks_panicked_total",
+			Help: "Number of tasks which panicked",
+		}, labels)),
+		duration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "parallel_task_duration_seconds",
+			Help:    "Duration of task runs",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 12),
+		}, labels)),
+	}
+}
+
+// register registers the collector or returns the equal one registered
+// previously
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return are.ExistingCollector.(T)
+		}
+		panic(err)
+	}
+	return c
+}
+
+func (m *metrics) taskSpawned(name string) {
+	if m == nil {
+		return
+	}
+	m.spawned.WithLabelValues(name).Inc()
+}
+
+func (m *metrics) taskStarted(name string) {
+	if m == nil {
+		return
+	}
+	m.running.WithLabelValues(name).Inc()
+}
+
+func (m *metrics) taskFinished(name string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.running.WithLabelValues(name).Dec()
+	m.finished.WithLabelValues(name).Inc()
+	m.duration.WithLabelValues(name).Observe(duration.Seconds())
+	if err == nil {
+		return
+	}
+	if errors.As(err, new(PanicError)) {
+		m.panicked.WithLabelValues(name).Inc()
+	} else {
+		m.failed.WithLabelValues(name).Inc()
+	}
+}
@@ -0,0 +1,47 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+
+	g := NewGroup(ctx, WithMetrics(reg))
+	g.Spawn("ok", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+
+	g = NewGroup(ctx, WithMetrics(reg))
+	g.Spawn("ok", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	g.Spawn("failed", Continue, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.Error(t, g.Wait())
+
+	g = NewGroup(ctx, WithMetrics(reg))
+	g.Spawn("panicked", Continue, func(ctx context.Context) error {
+		panic("oops")
+	})
+	require.Error(t, g.Wait())
+
+	m := newMetrics(reg)
+	require.Equal(t, 2.0, testutil.ToFloat64(m.spawned.WithLabelValues("ok")))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.finished.WithLabelValues("ok")))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.running.WithLabelValues("ok")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.failed.WithLabelValues("failed")))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.panicked.WithLabelValues("failed")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.panicked.WithLabelValues("panicked")))
+	require.Equal(t, 0.0, testutil.ToFloat64(m.failed.WithLabelValues("panicked")))
+	require.Equal(t, 3, testutil.CollectAndCount(m.duration))
+}
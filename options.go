@@ -0,0 +1,140 @@
+package parallel
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// GroupOption configures a Group created by NewGroup
+type GroupOption func(g *Group)
+
+// WithMaxConcurrent limits the number of subtasks running simultaneously in
+// the group. Subtasks spawned beyond the limit are queued and started in the
+// order of priority (see WithPriority) and spawning as running ones finish.
+//
+// A non-positive n means no limit, which is the default.
+func WithMaxConcurrent(n int) GroupOption {
+	return func(g *Group) {
+		g.maxConcurrent = n
+	}
+}
+
+// WithDeterministicSchedule makes the group run one subtask at a time, like
+// WithMaxConcurrent(1) does, and start the queued subtasks of equal priority in
+// a pseudo-random order derived from the seed instead of the order of
+// spawning. Given the same seed and the same sequence of spawns, the subtasks
+// start and finish in the same order every time, so a failure caused by a
+// particular ordering can be reproduced, and different seeds explore
+// different orderings. A subtask spawned while no other one is running starts
+// immediately, so spawn the subtasks while the group is paused, see
+// Group.Pause, to have all of them ordered by the seed.
+//
+// The subtasks must not wait for each other, otherwise they deadlock.
+func WithDeterministicSchedule(seed int64) GroupOption {
+	return func(g *Group) {
+		g.maxConcurrent = 1
+		g.queue.shuffle = rand.New(rand.NewSource(seed)) //nolint:gosec // the sequence needs to be reproducible
+	}
+}
+
+// WithSynchronous makes Spawn run the subtask in the calling goroutine and
+// return after it finishes, so subtasks run one by one in a predictable order
+// without goroutine interleaving. It is meant for unit tests and debugging.
+//
+// A queued subtask, see WithMaxConcurrent and Group.Pause, runs in the goroutine
+// which frees its slot or calls Resume. Subtasks waiting for each other,
+// including the ones owning subgroups, deadlock.
+func WithSynchronous() GroupOption {
+	return func(g *Group) {
+		g.synchronous = true
+	}
+}
+
+// WithGoroutineReuse makes the group run subtasks in goroutines left idle by
+// the finished ones instead of starting a new goroutine for each subtask. An
+// idle goroutine exits if no subtask is spawned within the given time. It pays
+// off for groups spawning many short subtasks.
+func WithGoroutineReuse(idle time.Duration) GroupOption {
+	return func(g *Group) {
+		g.reuse = idle
+		g.idle = make(chan *subtask)
+	}
+}
+
+// WithRestartBackoff configures delays between restarts of subtasks spawned in
+// Restart mode. DefaultBackoff is used if not configured.
+func WithRestartBackoff(b Backoff) GroupOption {
+	return func(g *Group) {
+		g.restartBackoff = b
+	}
+}
+
+// WithErrorAggregation makes the group result contain errors of all the failed
+// subtasks, joined by errors.Join, instead of only the first one.
+//
+// The first failure still causes the group to shut down. Errors returned by
+// subtasks after that, except context.Canceled, are collected as well.
+func WithErrorAggregation() GroupOption {
+	return func(g *Group) {
+		g.aggregateErrors = true
+	}
+}
+
+// WithMaxErrors makes the group tolerate up to n errors returned by subtasks
+// spawned in Continue mode. The tolerated errors are logged and reported by
+// Group.ToleratedErrors, and they don't cause the group to shut down. The next
+// error exhausting the budget is handled as usual.
+func WithMaxErrors(n int) GroupOption {
+	return func(g *Group) {
+		g.maxErrors = n
+	}
+}
+
+// WithSpawnRate throttles spawning of subtasks using a token bucket refilled at
+// rate r and holding up to burst tokens. Spawn blocks until a token is
+// available or the group starts shutting down.
+func WithSpawnRate(r rate.Limit, burst int) GroupOption {
+	return func(g *Group) {
+		g.spawnLimiter = rate.NewLimiter(r, burst)
+	}
+}
+
+// Middleware wraps a task to add behavior common to many tasks, like timing or
+// tracing
+type Middleware func(next Task) Task
+
+// WithTaskMiddleware makes the group wrap every subtask in the given
+// middleware, the first one being the outermost. Subgroups inherit the
+// middleware, adding their own inside.
+func WithTaskMiddleware(mw ...Middleware) GroupOption {
+	return func(g *Group) {
+		g.middleware = append(g.middleware, mw...)
+	}
+}
+
+// SpawnOption configures a single subtask spawned by Group.SpawnWith,
+// Group.SpawnE or Group.SpawnHandle, or submitted to a Pool
+type SpawnOption func(st *subtask)
+
+// WithTaskTimeout limits the duration of the subtask. When the timeout
+// expires, the context of the subtask closes. If the subtask then returns an
+// error, it is wrapped into an error naming the subtask and the timeout.
+//
+// In Restart mode the timeout applies to every run of the subtask separately.
+func WithTaskTimeout(timeout time.Duration) SpawnOption {
+	return func(st *subtask) {
+		st.timeout = timeout
+	}
+}
+
+// WithPriority sets the priority of the subtask. If the subtask has to wait in
+// the queue of a group created with WithMaxConcurrent, or of a Pool, it is
+// started before all the waiting subtasks of lower priority. Subtasks of equal
+// priority are started in the order of spawning. The default priority is 0.
+func WithPriority(priority int) SpawnOption {
+	return func(st *subtask) {
+		st.priority = priority
+	}
+}
@@ -0,0 +1,136 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// WithOutput makes the subtask, and the subtasks of its subgroups, write
+// their standard output and error to the writers, see Stdout and Stderr. A nil
+// writer means the one of the group is used.
+func WithOutput(stdout, stderr io.Writer) SpawnOption {
+	return func(st *subtask) {
+		st.output = &output{stdout: stdout, stderr: stderr}
+	}
+}
+
+// WithPrefixedOutput makes the subtask write its standard output and error to
+// those of the group, with every line prefixed by the name of the subtask,
+// e.g. "db | listening on :5432". Nested prefixed subtasks get all the names
+// of their parents, e.g. "db | migrate | done". An incomplete last line is
+// written when the subtask finishes.
+func WithPrefixedOutput() SpawnOption {
+	return func(st *subtask) {
+		st.prefixOutput = true
+	}
+}
+
+// Stdout returns the writer for the standard output of the subtask running
+// with ctx, see WithOutput. If none is configured, os.Stdout is returned.
+func Stdout(ctx context.Context) io.Writer {
+	if out, ok := ctx.Value(outputKey).(*output); ok {
+		return out.stdout
+	}
+	return os.Stdout
+}
+
+// Stderr returns the writer for the standard error of the subtask running
+// with ctx, see WithOutput. If none is configured, os.Stderr is returned.
+func Stderr(ctx context.Context) io.Writer {
+	if out, ok := ctx.Value(outputKey).(*output); ok {
+		return out.stderr
+	}
+	return os.Stderr
+}
+
+type output struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// outputFor resolves the writers of the subtask in the context of its group
+func outputFor(ctx context.Context, st *subtask) *output {
+	out := &output{stdout: Stdout(ctx), stderr: Stderr(ctx)}
+	if st.output != nil && st.output.stdout != nil {
+		out.stdout = st.output.stdout
+	}
+	if st.output != nil && st.output.stderr != nil {
+		out.stderr = st.output.stderr
+	}
+	if st.prefixOutput {
+		out.stdout = NewPrefixWriter(out.stdout, st.name+" | ")
+		out.stderr = NewPrefixWriter(out.stderr, st.name+" | ")
+	}
+	return out
+}
+
+// flush writes the incomplete lines buffered by prefix writers
+func (out *output) flush() {
+	for _, w := range []io.Writer{out.stdout, out.stderr} {
+		if pw, ok := w.(*PrefixWriter); ok {
+			_ = pw.Flush()
+		}
+	}
+}
+
+// PrefixWriter writes the lines written to it to the underlying writer,
+// prefixed by a fixed string. Each line is passed to the underlying writer in
+// a single Write call, so lines written concurrently by several subtasks are
+// not mixed up. PrefixWriter is safe for concurrent use.
+type PrefixWriter struct {
+	w      io.Writer
+	prefix []byte
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewPrefixWriter creates a PrefixWriter writing to w
+func NewPrefixWriter(w io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: []byte(prefix)}
+}
+
+// Write writes the complete lines of p, buffering the incomplete last one
+// until it's completed by a subsequent Write or Flush is called
+func (pw *PrefixWriter) Write(p []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			pw.buf = append(pw.buf, p...)
+			break
+		}
+		if err := pw.writeLine(p[:i+1]); err != nil {
+			return n - len(p), err
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush writes the buffered incomplete line, terminating it with a newline
+func (pw *PrefixWriter) Flush() error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if len(pw.buf) == 0 {
+		return nil
+	}
+	return pw.writeLine([]byte{'\n'})
+}
+
+// writeLine writes the buffered start of the line followed by rest, which ends
+// with a newline. The mutex must be held.
+func (pw *PrefixWriter) writeLine(rest []byte) error {
+	line := make([]byte, 0, len(pw.prefix)+len(pw.buf)+len(rest))
+	line = append(append(append(line, pw.prefix...), pw.buf...), rest...)
+	pw.buf = pw.buf[:0]
+	_, err := pw.w.Write(line)
+	return err
+}
@@ -0,0 +1,69 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pw := NewPrefixWriter(&buf, "> ")
+
+	n, err := pw.Write([]byte("one\ntw"))
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	require.Equal(t, "> one\n", buf.String())
+
+	_, err = pw.Write([]byte("o\nthree"))
+	require.NoError(t, err)
+	require.Equal(t, "> one\n> two\n", buf.String())
+
+	require.NoError(t, pw.Flush())
+	require.NoError(t, pw.Flush())
+	require.Equal(t, "> one\n> two\n> three\n", buf.String())
+}
+
+func TestGroupOutput(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, os.Stdout, Stdout(ctx))
+	require.Equal(t, os.Stderr, Stderr(ctx))
+
+	var stdout, stderr bytes.Buffer
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		g := NewGroup(ctx)
+		g.SpawnWith("svc", Continue, func(ctx context.Context) error {
+			fmt.Fprintln(Stdout(ctx), "starting")
+			return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+				g := NewGroup(ctx)
+				g.SpawnWith("db", Continue, func(ctx context.Context) error {
+					fmt.Fprint(Stdout(ctx), "listening\nrea")
+					fmt.Fprint(Stderr(ctx), "warning\n")
+					return nil
+				}, WithPrefixedOutput())
+				return g.Wait()
+			})
+		}, WithOutput(&stdout, &stderr), WithPrefixedOutput())
+		return g.Wait()
+	})
+	require.NoError(t, err)
+	require.Equal(t, "svc | starting\nsvc | db | listening\nsvc | db | rea\n", stdout.String())
+	require.Equal(t, "svc | db | warning\n", stderr.String())
+}
+
+func TestCommandOutput(t *testing.T) {
+	ctx := context.Background()
+
+	var stdout bytes.Buffer
+	g := NewGroup(ctx)
+	g.SpawnWith("echo", Continue, Command("echo", exec.Command("echo", "hello"), time.Second),
+		WithOutput(&stdout, nil), WithPrefixedOutput())
+	require.NoError(t, g.Wait())
+	require.Equal(t, "echo | hello\n", stdout.String())
+}
@@ -0,0 +1,150 @@
+// Package paralleltest provides helpers for testing code built on package
+// parallel.
+package paralleltest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/outofforest/parallel"
+)
+
+// Clock is a fake parallel.Clock, its time moves only when Advance is called
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*timer
+	changed chan struct{}
+}
+
+// NewClock creates a fake clock showing the given time
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now, changed: make(chan struct{})}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer creates a timer firing once the clock is advanced by d
+func (c *Clock) NewTimer(d time.Duration) parallel.Timer {
+	t := &timer{clock: c, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// AfterFunc creates a timer calling f once the clock is advanced by d
+func (c *Clock) AfterFunc(d time.Duration, f func()) parallel.Timer {
+	t := &timer{clock: c, fn: f}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers expiring meanwhile
+// in the order of their expiry
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].when.Before(c.timers[j].when)
+		})
+		if len(c.timers) == 0 || c.timers[0].when.After(end) {
+			break
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.when
+		t.fire(c.now)
+	}
+	c.now = end
+}
+
+// WaitForTimers blocks until at least n timers are pending, so the test may
+// advance the clock once the code under test has started waiting
+func (c *Clock) WaitForTimers(n int) {
+	for {
+		c.mu.Lock()
+		pending := len(c.timers)
+		changed := c.changed
+		c.mu.Unlock()
+
+		if pending >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// Timers returns the number of pending timers
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// remove removes the timer from the pending ones, the mutex must be held
+func (c *Clock) remove(t *timer) bool {
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type timer struct {
+	clock *Clock
+	ch    chan time.Time
+	fn    func()
+	when  time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.remove(t)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := c.remove(t)
+	t.when = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return active
+	}
+	c.timers = append(c.timers, t)
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return active
+}
+
+// fire fires the timer, the mutex of the clock must be held
+func (t *timer) fire(now time.Time) {
+	if t.fn != nil {
+		go t.fn()
+		return
+	}
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
@@ -0,0 +1,82 @@
+package paralleltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/parallel"
+)
+
+func TestClockTimers(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+
+	t1 := clock.NewTimer(time.Second)
+	t2 := clock.NewTimer(2 * time.Second)
+	fired := make(chan struct{})
+	clock.AfterFunc(time.Second, func() {
+		close(fired)
+	})
+	require.Equal(t, 3, clock.Timers())
+
+	clock.Advance(time.Second)
+	<-fired
+	require.Equal(t, time.Unix(1, 0), <-t1.C())
+	require.Empty(t, t2.C())
+	require.True(t, t2.Stop())
+	require.False(t, t2.Stop())
+	require.Zero(t, clock.Timers())
+}
+
+func TestClockGroup(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	g := parallel.NewGroup(context.Background(), parallel.WithClock(clock),
+		parallel.WithRestartBackoff(parallel.Backoff{Initial: time.Hour, Max: time.Hour}))
+
+	var runs int
+	g.Spawn("restarted", parallel.Restart, func(ctx context.Context) error {
+		runs++
+		if runs == 1 {
+			return errors.New("oops")
+		}
+		return parallel.Permanent(errors.New("fatal"))
+	})
+	g.SpawnWith("slow", parallel.Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, parallel.WithTaskTimeout(2*time.Hour))
+
+	clock.WaitForTimers(2)
+	clock.Advance(time.Hour)
+	require.EqualError(t, g.Wait(), "fatal")
+	require.Equal(t, 2, runs)
+}
+
+func TestClockTimeout(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	ctx := parallel.ContextWithClock(context.Background(), clock)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- parallel.RunWithTimeout(ctx, time.Hour, func(ctx context.Context, spawn parallel.SpawnFn) error {
+			spawn("slow", parallel.Fail, func(ctx context.Context) error {
+				deadline, ok := ctx.Deadline()
+				if !ok || !deadline.Equal(time.Unix(3600, 0)) {
+					return errors.New("wrong deadline")
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			return nil
+		})
+	}()
+
+	clock.WaitForTimers(1)
+	clock.Advance(time.Hour)
+	err := <-result
+	require.ErrorAs(t, err, new(parallel.TimeoutError))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,35 @@
+package paralleltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/outofforest/parallel"
+)
+
+// NewGroup creates a group for the test. Its context is closed once the
+// deadline of the test approaches. When the test ends, the group is prompted to
+// exit, and the test fails if the group returns an error.
+func NewGroup(t testing.TB, opts ...parallel.GroupOption) *parallel.Group {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if d, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := d.Deadline(); ok {
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+		}
+	}
+
+	g := parallel.NewGroup(ctx, opts...)
+	t.Cleanup(func() {
+		t.Helper()
+		defer cancel()
+
+		g.Exit(nil)
+		if err := g.Wait(); err != nil {
+			t.Errorf("group failed: %s", err)
+		}
+	})
+	return g
+}
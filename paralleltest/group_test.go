@@ -0,0 +1,39 @@
+package paralleltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/parallel"
+)
+
+func TestNewGroup(t *testing.T) {
+	rt := &recordingT{TB: t}
+	g := NewGroup(rt)
+
+	canceled := make(chan error, 1)
+	g.Spawn("task", parallel.Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return ctx.Err()
+	})
+
+	rt.finish()
+	require.ErrorIs(t, <-canceled, context.Canceled)
+	require.Empty(t, rt.errors)
+}
+
+func TestNewGroupFailed(t *testing.T) {
+	rt := &recordingT{TB: t}
+	g := NewGroup(rt)
+
+	g.Spawn("task", parallel.Continue, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+
+	rt.finish()
+	require.Equal(t, []string{"group failed: oops"}, rt.errors)
+}
@@ -0,0 +1,46 @@
+package paralleltest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/outofforest/parallel"
+)
+
+// leakTimeout is the time the subtasks are given to finish after the test ends
+const leakTimeout = time.Second
+
+// VerifyNoLeaks makes the test fail if any subtask of the group, including the
+// ones of its subgroups, is still running when the test ends. The subtasks are
+// given a moment to finish, then the leaked ones are reported by name with
+// their stack traces.
+func VerifyNoLeaks(t testing.TB, group *parallel.Group) {
+	t.Helper()
+	t.Cleanup(func() {
+		t.Helper()
+
+		deadline := time.Now().Add(leakTimeout)
+		leaked := group.Unfinished()
+		for len(leaked) > 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			leaked = group.Unfinished()
+		}
+		if len(leaked) == 0 {
+			return
+		}
+
+		var report strings.Builder
+		for _, task := range leaked {
+			fmt.Fprintf(&report, "\ntask %s (%x):\n", task.Name, task.ID)
+			if task.Stack == "" {
+				report.WriteString("queued\n")
+				continue
+			}
+			report.WriteString(task.Stack)
+			report.WriteString("\n")
+		}
+		t.Errorf("%d tasks leaked:%s", len(leaked), report.String())
+	})
+}
@@ -0,0 +1,70 @@
+package paralleltest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/parallel"
+)
+
+type recordingT struct {
+	testing.TB
+
+	cleanups []func()
+	errors   []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	g := parallel.NewGroup(context.Background())
+	VerifyNoLeaks(t, g)
+
+	g.Spawn("task", parallel.Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+}
+
+func TestVerifyNoLeaksLeaked(t *testing.T) {
+	g := parallel.NewGroup(context.Background())
+	rt := &recordingT{TB: t}
+	VerifyNoLeaks(rt, g)
+
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		g.Exit(nil)
+		require.NoError(t, g.Wait())
+	}()
+
+	sub := parallel.NewSubgroup(g.Spawn, "sub", parallel.Continue)
+	sub.Spawn("leaked", parallel.Continue, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	rt.finish()
+	require.Len(t, rt.errors, 1)
+	require.Contains(t, rt.errors[0], "2 tasks leaked")
+	require.Contains(t, rt.errors[0], "task sub (")
+	require.Contains(t, rt.errors[0], "task sub.leaked (")
+	require.Contains(t, rt.errors[0], "leaks_test.go")
+}
@@ -0,0 +1,114 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"sync"
+)
+
+// Partitioned processes items on a fixed set of workers, always passing the
+// items submitted with the same key to the same worker, so items of a key are
+// processed one by one in the order of submitting, while different keys are
+// processed in parallel.
+//
+// Like Pool, Partitioned is a task itself, run by its Run method.
+type Partitioned[K comparable, T any] struct {
+	hash    func(key K) uint64
+	handler func(ctx context.Context, item T) error
+	queues  []chan T
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPartitioned creates a worker set with the given number of workers, each
+// with a queue of the given capacity, passing items to handler. Keys are
+// assigned to workers by hash. If hash is nil, keys are hashed by their string
+// representation.
+func NewPartitioned[K comparable, T any](workers, queueSize int, hash func(key K) uint64, handler func(ctx context.Context, item T) error) *Partitioned[K, T] {
+	if workers < 1 {
+		workers = 1
+	}
+	if hash == nil {
+		seed := maphash.MakeSeed()
+		hash = func(key K) uint64 {
+			return maphash.String(seed, fmt.Sprint(key))
+		}
+	}
+	p := &Partitioned[K, T]{
+		hash:    hash,
+		handler: handler,
+		queues:  make([]chan T, workers),
+		done:    make(chan struct{}),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan T, queueSize)
+	}
+	return p
+}
+
+// Submit queues the item for the worker owning the key, blocking while its
+// queue is full. If ctx closes first, ctx.Err() is returned. ErrPoolClosed is
+// returned if the worker set has been closed or has finished running.
+func (p *Partitioned[K, T]) Submit(ctx context.Context, key K, item T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.queues[p.hash(key)%uint64(len(p.queues))] <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return ErrPoolClosed
+	}
+}
+
+// Close stops accepting new items. Items queued so far are still processed,
+// then the workers exit and Run returns.
+func (p *Partitioned[K, T]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.closed {
+		p.closed = true
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+}
+
+// Run runs the workers until the worker set is closed and drained, ctx closes
+// or handler fails. When Run returns, the worker set is closed. Run must be
+// called once.
+func (p *Partitioned[K, T]) Run(ctx context.Context) error {
+	defer p.Close()
+	defer close(p.done)
+
+	return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		for i, queue := range p.queues {
+			queue := queue
+			spawn(fmt.Sprintf("worker-%d", i), Continue, func(ctx context.Context) error {
+				for {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case item, ok := <-queue:
+						if !ok {
+							return nil
+						}
+						if err := p.handler(ctx, item); err != nil {
+							return err
+						}
+					}
+				}
+			})
+		}
+		return nil
+	})
+}
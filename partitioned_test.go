@@ -0,0 +1,69 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedItem struct {
+	key string
+	seq int
+}
+
+func TestPartitioned(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	seen := map[string][]int{}
+	p := NewPartitioned[string, keyedItem](4, 2, nil, func(ctx context.Context, item keyedItem) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[item.key] = append(seen[item.key], item.seq)
+		return nil
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- p.Run(ctx)
+	}()
+
+	keys := []string{"a", "b", "c", "d", "e"}
+	for seq := 0; seq < 20; seq++ {
+		for _, key := range keys {
+			require.NoError(t, p.Submit(ctx, key, keyedItem{key: key, seq: seq}))
+		}
+	}
+	p.Close()
+	require.NoError(t, <-result)
+	require.ErrorIs(t, p.Submit(ctx, "a", keyedItem{}), ErrPoolClosed)
+
+	for _, key := range keys {
+		require.Len(t, seen[key], 20)
+		for i, seq := range seen[key] {
+			require.Equal(t, i, seq)
+		}
+	}
+}
+
+func TestPartitionedError(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewPartitioned(2, 0, func(key int) uint64 {
+		return uint64(key)
+	}, func(ctx context.Context, item int) error {
+		return errors.New("oops")
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- p.Run(ctx)
+	}()
+
+	require.NoError(t, p.Submit(ctx, 1, 1))
+	require.EqualError(t, <-result, "oops")
+	require.ErrorIs(t, p.Submit(ctx, 1, 2), ErrPoolClosed)
+}
@@ -0,0 +1,67 @@
+package parallel
+
+import "context"
+
+// Pause pauses the group. Subtasks spawned or queued (see WithMaxConcurrent)
+// while the group is paused aren't started until Resume is called. Running
+// subtasks are not affected, but they may watch Paused to stop taking new
+// work.
+//
+// When the group context closes, the group is resumed, so the queued subtasks
+// may finish.
+func (g *Group) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused || g.ctx.Err() != nil {
+		return
+	}
+	g.paused = true
+	close(g.pausing)
+	g.unpause = context.AfterFunc(g.ctx, g.Resume)
+}
+
+// Resume resumes the group paused by Pause, starting the queued subtasks
+func (g *Group) Resume() {
+	g.mu.Lock()
+	if !g.paused {
+		g.mu.Unlock()
+		return
+	}
+	g.paused = false
+	g.pausing = make(chan struct{})
+	g.unpause()
+	g.unpause = nil
+	for g.queue.Len() > 0 && (g.maxConcurrent <= 0 || g.active < g.maxConcurrent) {
+		g.active++
+		g.launch(g.queue.Pop())
+	}
+	for _, l := range g.lanes {
+		for l.queue.Len() > 0 && !l.full() {
+			l.active++
+			g.launch(l.queue.Pop())
+		}
+	}
+	g.mu.Unlock()
+
+	if g.synchronous {
+		g.runScheduled()
+	}
+}
+
+// Paused returns a channel which is closed when the group of the subtask
+// running with ctx gets paused, see Group.Pause. After the group is resumed,
+// Paused returns a new channel. If ctx doesn't belong to a subtask, nil is
+// returned.
+func Paused(ctx context.Context) <-chan struct{} {
+	st, ok := ctx.Value(subtaskKey).(*subtask)
+	if !ok {
+		return nil
+	}
+
+	g := st.group
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.pausing
+}
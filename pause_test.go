@@ -0,0 +1,64 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPause(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(1))
+
+	paused := make(chan struct{})
+	release := make(chan struct{})
+	g.Spawn("running", Continue, func(ctx context.Context) error {
+		<-Paused(ctx)
+		close(paused)
+		<-release
+		return nil
+	})
+	g.Spawn("queued", Continue, func(ctx context.Context) error {
+		return nil
+	})
+
+	g.Pause()
+	<-paused
+	g.Spawn("spawned", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	close(release)
+
+	require.Eventually(t, func() bool {
+		return g.Running() == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, map[string]TaskState{"queued": TaskQueued, "spawned": TaskQueued}, taskStates(g))
+
+	g.Resume()
+	require.NoError(t, g.Wait())
+}
+
+func TestPauseExit(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.Pause()
+	g.Spawn("queued", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func taskStates(g *Group) map[string]TaskState {
+	states := map[string]TaskState{}
+	for _, info := range g.Tasks() {
+		if info.State != TaskFinished {
+			states[info.Name] = info.State
+		}
+	}
+	return states
+}
@@ -0,0 +1,178 @@
+package parallel
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// PeriodicOption configures a periodic task created by Periodic
+type PeriodicOption func(p *periodic)
+
+// WithJitter makes the delay between runs of a periodic task randomly
+// decreased by up to the given fraction of the interval, so periodic tasks of
+// many processes don't run in lockstep
+func WithJitter(fraction float64) PeriodicOption {
+	return func(p *periodic) {
+		p.jitter = fraction
+	}
+}
+
+// WithImmediateStart makes a periodic task run for the first time immediately
+// instead of after the first interval
+func WithImmediateStart() PeriodicOption {
+	return func(p *periodic) {
+		p.immediate = true
+	}
+}
+
+// WithCatchUp makes a task created by Tick run the ticks it has missed, one
+// after another, instead of skipping them
+func WithCatchUp() PeriodicOption {
+	return func(p *periodic) {
+		p.catchUp = true
+	}
+}
+
+type periodic struct {
+	interval  time.Duration
+	task      Task
+	jitter    float64
+	immediate bool
+	catchUp   bool
+}
+
+// Periodic returns a task running the given task every interval, measured
+// between the starts of consecutive runs. If a run takes longer than the
+// interval, the next one starts immediately after it.
+//
+// The returned task returns the first error returned by the given task, or
+// ctx.Err() when ctx closes.
+//
+// Example:
+//
+//	spawn("cleanup", parallel.Fail, parallel.Periodic(time.Hour, cleanup, parallel.WithImmediateStart()))
+func Periodic(interval time.Duration, task Task, opts ...PeriodicOption) Task {
+	p := &periodic{interval: interval, task: task}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p.run
+}
+
+// Tick returns a task calling fn on ticks scheduled every interval since the
+// task has started, so unlike Periodic it doesn't drift. The scheduled time of
+// the tick is passed to fn. Ticks missed because fn has taken longer than the
+// interval are skipped, unless WithCatchUp is used. WithJitter doesn't apply.
+//
+// The returned task returns the first error returned by fn, or ctx.Err() when
+// ctx closes.
+func Tick(interval time.Duration, fn func(ctx context.Context, tick time.Time) error, opts ...PeriodicOption) Task {
+	p := &periodic{interval: interval}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return func(ctx context.Context) error {
+		clock := clockFromContext(ctx)
+		next := clock.Now()
+		if !p.immediate {
+			next = next.Add(interval)
+		}
+		timer := clock.NewTimer(next.Sub(clock.Now()))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C():
+			}
+
+			for {
+				if err := fn(ctx, next); err != nil {
+					return err
+				}
+				next = next.Add(interval)
+				now := clock.Now()
+				if !next.Before(now) {
+					break
+				}
+				if !p.catchUp {
+					next = next.Add((now.Sub(next)/interval + 1) * interval)
+					break
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+			}
+			timer.Reset(next.Sub(clock.Now()))
+		}
+	}
+}
+
+// SpawnPeriodic spawns a subtask running the given task every interval, see
+// Periodic
+func (g *Group) SpawnPeriodic(name string, onExit OnExit, interval time.Duration, task Task, opts ...PeriodicOption) {
+	g.Spawn(name, onExit, Periodic(interval, task, opts...))
+}
+
+func (p *periodic) run(ctx context.Context) error {
+	var first time.Duration
+	if !p.immediate {
+		first = p.delay(0)
+	}
+	clock := clockFromContext(ctx)
+	timer := clock.NewTimer(first)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+		}
+
+		started := clock.Now()
+		if err := p.task(ctx); err != nil {
+			return err
+		}
+		timer.Reset(p.delay(clock.Now().Sub(started)))
+	}
+}
+
+// delay returns the time to wait before the next run, given the duration of
+// the previous one
+func (p *periodic) delay(elapsed time.Duration) time.Duration {
+	delay := p.interval
+	if p.jitter > 0 {
+		delay -= time.Duration(float64(delay) * p.jitter * rand.Float64()) //nolint:gosec // jitter does not need cryptographic randomness
+	}
+	if delay -= elapsed; delay < 0 {
+		delay = 0
+	}
+	return delay
+}
+
+// Delayed returns a task running the given task after the delay. If ctx closes
+// before the delay expires, the given task is never run and ctx.Err() is
+// returned.
+func Delayed(delay time.Duration, task Task) Task {
+	return func(ctx context.Context) error {
+		timer := clockFromContext(ctx).NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+		}
+		return task(ctx)
+	}
+}
+
+// SpawnAfter spawns a subtask running the given task after the delay, see
+// Delayed. The subtask is considered running, and keeps the group from
+// finishing, during the delay.
+func (g *Group) SpawnAfter(name string, onExit OnExit, delay time.Duration, task Task, opts ...SpawnOption) {
+	g.SpawnWith(name, onExit, Delayed(delay, task), opts...)
+}
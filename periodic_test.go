@@ -0,0 +1,111 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodic(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	var runs []time.Time
+	started := time.Now()
+	g.SpawnPeriodic("periodic", Fail, 10*time.Millisecond, func(ctx context.Context) error {
+		runs = append(runs, time.Now())
+		if len(runs) == 3 {
+			return errors.New("done")
+		}
+		return nil
+	})
+	require.EqualError(t, g.Wait(), "done")
+	require.Len(t, runs, 3)
+	require.GreaterOrEqual(t, runs[0].Sub(started), 10*time.Millisecond)
+	require.GreaterOrEqual(t, runs[2].Sub(runs[1]), 10*time.Millisecond)
+}
+
+func TestPeriodicImmediate(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	ran := make(chan struct{})
+	g.Spawn("periodic", Fail, Periodic(time.Hour, func(ctx context.Context) error {
+		close(ran)
+		return nil
+	}, WithImmediateStart(), WithJitter(0.5)))
+	<-ran
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestPeriodicDelay(t *testing.T) {
+	p := &periodic{interval: time.Second, jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := p.delay(100 * time.Millisecond)
+		require.LessOrEqual(t, d, 900*time.Millisecond)
+		require.GreaterOrEqual(t, d, 400*time.Millisecond)
+	}
+	require.Zero(t, p.delay(2*time.Second))
+}
+
+func TestSpawnAfter(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	started := time.Now()
+	var ran time.Time
+	g.SpawnAfter("delayed", Continue, 10*time.Millisecond, func(ctx context.Context) error {
+		ran = time.Now()
+		return nil
+	})
+	require.NoError(t, g.Wait())
+	require.GreaterOrEqual(t, ran.Sub(started), 10*time.Millisecond)
+}
+
+func TestSpawnAfterCanceled(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.SpawnAfter("delayed", Fail, time.Hour, func(ctx context.Context) error {
+		t.Fatal("must not be run")
+		return nil
+	})
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestTick(t *testing.T) {
+	ctx := context.Background()
+	const interval = 10 * time.Millisecond
+
+	run := func(opts ...PeriodicOption) []time.Time {
+		var ticks []time.Time
+		err := Tick(interval, func(ctx context.Context, tick time.Time) error {
+			ticks = append(ticks, tick)
+			if len(ticks) == 1 {
+				time.Sleep(25 * time.Millisecond)
+			}
+			if len(ticks) == 4 {
+				return errors.New("done")
+			}
+			return nil
+		}, opts...)(ctx)
+		require.EqualError(t, err, "done")
+		return ticks
+	}
+
+	ticks := run(WithImmediateStart())
+	require.GreaterOrEqual(t, ticks[1].Sub(ticks[0]), 3*interval)
+	for i := 1; i < len(ticks); i++ {
+		require.Zero(t, ticks[i].Sub(ticks[0])%interval)
+	}
+
+	ticks = run(WithImmediateStart(), WithCatchUp())
+	for i := 1; i < len(ticks); i++ {
+		require.Equal(t, interval, ticks[i].Sub(ticks[i-1]))
+	}
+}
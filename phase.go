@@ -0,0 +1,92 @@
+package parallel
+
+import "context"
+
+// WithPhase assigns the subtask to the named phase of the group.
+//
+// Phases are ordered by their first use in the group, so the subtasks of the
+// phase used first should be spawned first, e.g. storage before the API
+// served from it. When the group shuts down, the phases are shut down in the
+// reverse order: the contexts of the subtasks of a phase are closed only once
+// all the subtasks of the later phases have finished. The contexts of the
+// subtasks not assigned to any phase are closed immediately.
+func WithPhase(name string) SpawnOption {
+	return func(st *subtask) {
+		st.phaseName = name
+	}
+}
+
+type phase struct {
+	name   string
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	// Protected by the mutex of the group
+	running int
+	idle    chan struct{}
+}
+
+// phase returns the phase of the given name, creating it on first use. The
+// mutex must be held.
+func (g *Group) phase(name string) *phase {
+	for _, p := range g.phases {
+		if p.name == name {
+			return p
+		}
+	}
+
+	p := &phase{name: name, idle: make(chan struct{})}
+	close(p.idle)
+	p.ctx, p.cancel = context.WithCancelCause(context.WithoutCancel(g.ctx))
+	if len(g.phases) == 0 {
+		context.AfterFunc(g.ctx, g.shutdownPhases)
+	}
+	if g.ctx.Err() != nil {
+		// The phase is created during shutdown
+		p.cancel(context.Cause(g.ctx))
+	}
+	g.phases = append(g.phases, p)
+	return p
+}
+
+// shutdownPhases closes the contexts of the phases in the reverse order,
+// waiting for each phase to drain before closing the previous one
+func (g *Group) shutdownPhases() {
+	cause := context.Cause(g.ctx)
+
+	g.mu.Lock()
+	phases := append([]*phase(nil), g.phases...)
+	g.mu.Unlock()
+
+	for i := len(phases) - 1; i >= 0; i-- {
+		p := phases[i]
+		p.cancel(cause)
+
+		g.mu.Lock()
+		idle := p.idle
+		g.mu.Unlock()
+		<-idle
+	}
+}
+
+// add counts a subtask spawned in the phase, the mutex must be held
+func (p *phase) add() {
+	if p == nil {
+		return
+	}
+	if p.running == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.running++
+}
+
+// remove counts a subtask finished in the phase, the mutex must be held
+func (p *phase) remove() {
+	if p == nil {
+		return
+	}
+	p.running--
+	if p.running == 0 {
+		close(p.idle)
+	}
+}
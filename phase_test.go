@@ -0,0 +1,42 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhases(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	var mu sync.Mutex
+	var order []string
+	started := make(chan struct{}, 4)
+	task := func(name string) Task {
+		return func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return ctx.Err()
+		}
+	}
+
+	g.SpawnWith("db", Fail, task("db"), WithPhase("storage"))
+	g.SpawnWith("http", Fail, task("http"), WithPhase("api"))
+	g.SpawnWith("grpc", Fail, task("grpc"), WithPhase("api"))
+	g.Spawn("unphased", Fail, task("unphased"))
+	for i := 0; i < 4; i++ {
+		<-started
+	}
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.Len(t, order, 4)
+	require.Equal(t, "db", order[3])
+	require.ElementsMatch(t, []string{"unphased", "http", "grpc"}, order[:3])
+}
@@ -0,0 +1,123 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pipeline is a set of stages connected by channels. Every stage runs a number
+// of workers in its own subgroup, and closes its output channel when all its
+// workers finish. If any stage fails, the whole pipeline is shut down.
+//
+// Stages are added using Source, Stage and Sink functions:
+//
+//	p := parallel.NewPipeline()
+//	lines := parallel.Source(p, "read", func(ctx context.Context, out chan<- string) error {
+//	    return readLines(ctx, file, out)
+//	})
+//	records := parallel.Stage(p, "parse", 4, lines, parseRecord)
+//	parallel.Sink(p, "store", 8, records, storeRecord)
+//	err := p.Run(ctx)
+type Pipeline struct {
+	stages []pipelineStage
+}
+
+type pipelineStage struct {
+	name string
+	run  Task
+}
+
+// NewPipeline creates a new empty pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Run runs all the stages of the pipeline until the data is fully processed,
+// any stage fails, or ctx closes. Run must be called once.
+func (p *Pipeline) Run(ctx context.Context) error {
+	return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		for _, s := range p.stages {
+			spawn(s.name, Continue, s.run)
+		}
+		return nil
+	})
+}
+
+// Source adds the first stage to the pipeline. The fn function sends items to
+// out, which is closed when fn returns. Sending must be aborted when ctx
+// closes.
+func Source[T any](p *Pipeline, name string, fn func(ctx context.Context, out chan<- T) error) <-chan T {
+	out := make(chan T)
+	p.stages = append(p.stages, pipelineStage{name: name, run: func(ctx context.Context) error {
+		defer close(out)
+		return fn(ctx, out)
+	}})
+	return out
+}
+
+// Stage adds an intermediate stage to the pipeline. The given number of
+// workers transform items received from in using fn and send the results to
+// the returned channel.
+func Stage[T, R any](p *Pipeline, name string, workers int, in <-chan T, fn func(ctx context.Context, item T) (R, error)) <-chan R {
+	out := make(chan R)
+	p.addWorkers(name, workers, func(ctx context.Context) error {
+		for {
+			item, ok, err := receive(ctx, in)
+			if !ok {
+				return err
+			}
+			res, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- res:
+			}
+		}
+	}, func() { close(out) })
+	return out
+}
+
+// Sink adds the final stage to the pipeline. The given number of workers
+// consume items received from in using fn.
+func Sink[T any](p *Pipeline, name string, workers int, in <-chan T, fn func(ctx context.Context, item T) error) {
+	p.addWorkers(name, workers, func(ctx context.Context) error {
+		for {
+			item, ok, err := receive(ctx, in)
+			if !ok {
+				return err
+			}
+			if err := fn(ctx, item); err != nil {
+				return err
+			}
+		}
+	}, func() {})
+}
+
+// addWorkers adds a stage running the given number of workers in a subgroup.
+// The done function is called when all the workers finish.
+func (p *Pipeline) addWorkers(name string, workers int, worker Task, done func()) {
+	p.stages = append(p.stages, pipelineStage{name: name, run: func(ctx context.Context) error {
+		defer done()
+
+		g := NewGroup(ctx)
+		for i := 0; i < workers; i++ {
+			g.Spawn(fmt.Sprintf("worker-%d", i), Continue, worker)
+		}
+		return g.Wait()
+	}})
+}
+
+// receive receives an item from the channel unless ctx closes first. The
+// second returned value is false if there are no more items to process.
+func receive[T any](ctx context.Context, in <-chan T) (T, bool, error) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, false, ctx.Err()
+	case item, ok := <-in:
+		return item, ok, nil
+	}
+}
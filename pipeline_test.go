@@ -0,0 +1,67 @@
+package parallel
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewPipeline()
+	numbers := Source(p, "generate", func(ctx context.Context, out chan<- int) error {
+		for i := 1; i <= 10; i++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- i:
+			}
+		}
+		return nil
+	})
+	squares := Stage(p, "square", 3, numbers, func(ctx context.Context, n int) (string, error) {
+		return strconv.Itoa(n * n), nil
+	})
+
+	var mu sync.Mutex
+	var results []string
+	Sink(p, "collect", 2, squares, func(ctx context.Context, s string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, s)
+		return nil
+	})
+
+	require.NoError(t, p.Run(ctx))
+	sort.Strings(results)
+	require.Equal(t, []string{"1", "100", "16", "25", "36", "4", "49", "64", "81", "9"}, results)
+}
+
+func TestPipelineError(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewPipeline()
+	numbers := Source(p, "generate", func(ctx context.Context, out chan<- int) error {
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case out <- i:
+			}
+		}
+	})
+	Sink(p, "fail", 2, numbers, func(ctx context.Context, n int) error {
+		if n == 5 {
+			return errors.New("oops")
+		}
+		return nil
+	})
+
+	require.EqualError(t, p.Run(ctx), "oops")
+}
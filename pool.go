@@ -0,0 +1,241 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrPoolClosed is returned by Pool.Submit and Partitioned.Submit if the pool
+// doesn't accept tasks anymore
+var ErrPoolClosed = errors.New("pool closed")
+
+// ErrQueueFull is returned by Pool.Submit if the queue is full and the pool
+// was created with OverflowReject policy
+var ErrQueueFull = errors.New("queue full")
+
+// OverflowPolicy is an enumeration of the ways Pool.Submit handles a full
+// queue
+type OverflowPolicy int
+
+const (
+	// OverflowBlock means Submit blocks until there is space in the queue
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowReject means Submit returns ErrQueueFull
+	OverflowReject
+
+	// OverflowDropOldest means the oldest task of the lowest priority is
+	// removed from the queue to make space for the submitted one. The removed
+	// task is never run.
+	OverflowDropOldest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "Block"
+	case OverflowReject:
+		return "Reject"
+	case OverflowDropOldest:
+		return "DropOldest"
+	default:
+		return fmt.Sprintf("invalid OverflowPolicy: %d", p)
+	}
+}
+
+// PoolOption configures a Pool
+type PoolOption func(p *Pool)
+
+// WithOverflowPolicy sets the way Submit handles a full queue, OverflowBlock
+// by default
+func WithOverflowPolicy(policy OverflowPolicy) PoolOption {
+	return func(p *Pool) {
+		p.overflow = policy
+	}
+}
+
+// Pool runs submitted tasks on a fixed set of worker goroutines, which is
+// cheaper than spawning a subtask for every task if there are many of them.
+//
+// Pool is a task itself. Its Run method runs the workers in a group until the
+// pool is closed and all the queued tasks are done, or until the context
+// closes. If any task returns an error or panics, the pool shuts down and Run
+// returns that error.
+//
+// Example:
+//
+//	pool := parallel.NewPool(16, 1024)
+//	spawn("pool", parallel.Exit, pool.Run)
+//	for _, item := range items {
+//	    item := item
+//	    if err := pool.Submit("item", func(ctx context.Context) error {
+//	        return process(ctx, item)
+//	    }); err != nil {
+//	        return err
+//	    }
+//	}
+//	pool.Close()
+type Pool struct {
+	workers   int
+	queueSize int
+	overflow  OverflowPolicy
+
+	// available is signaled when a task is queued, space is signaled when a
+	// task is taken from the queue. Signals are buffered, so waiting on them
+	// does not require holding the mutex.
+	available chan struct{}
+	space     chan struct{}
+	closing   chan struct{}
+
+	mu     sync.Mutex
+	queue  priorityQueue[*subtask]
+	closed bool
+}
+
+// NewPool creates a new pool with the given number of workers and the given
+// capacity of the queue of submitted tasks. A non-positive queueSize means the
+// queue is unbounded.
+func NewPool(workers, queueSize int, opts ...PoolOption) *Pool {
+	p := &Pool{
+		workers:   workers,
+		queueSize: queueSize,
+		available: make(chan struct{}, 1),
+		space:     make(chan struct{}, 1),
+		closing:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Submit queues the task for execution by one of the workers. If the queue is
+// full, Submit blocks until there is space in it, unless configured otherwise
+// by WithOverflowPolicy.
+//
+// The name of the task is only used for logging. Of the spawn options,
+// WithPriority and WithTaskTimeout are taken into account.
+//
+// ErrPoolClosed is returned if the pool has been closed or has finished
+// running.
+func (p *Pool) Submit(name string, task Task, opts ...SpawnOption) error {
+	job := &subtask{name: name, path: name, task: task}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return ErrPoolClosed
+		}
+		if p.queueSize <= 0 || p.queue.Len() < p.queueSize {
+			p.queue.Push(job, job.priority)
+			p.mu.Unlock()
+			notify(p.available)
+			return nil
+		}
+		switch p.overflow {
+		case OverflowReject:
+			p.mu.Unlock()
+			return ErrQueueFull
+		case OverflowDropOldest:
+			p.queue.DropOldest()
+			p.queue.Push(job, job.priority)
+			p.mu.Unlock()
+			return nil
+		}
+		p.mu.Unlock()
+
+		select {
+		case <-p.space:
+		case <-p.closing:
+		}
+	}
+}
+
+// Close stops accepting new tasks. Tasks queued so far are still executed,
+// then the workers exit and Run returns.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.closed {
+		p.closed = true
+		close(p.closing)
+	}
+}
+
+// Run runs the workers until the pool is closed and drained or ctx closes.
+// When Run returns, the pool is closed. Run must be called once.
+func (p *Pool) Run(ctx context.Context) error {
+	defer p.Close()
+
+	g := NewGroup(ctx)
+	for i := 0; i < p.workers; i++ {
+		g.Spawn(fmt.Sprintf("worker-%d", i), Continue, p.work)
+	}
+	return g.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) error {
+	log := loggerFromContext(ctx)
+	for {
+		job, ok := p.next(ctx)
+		if !ok {
+			return ctx.Err()
+		}
+		if group := GroupName(ctx); group != "" {
+			job.path = group + "." + job.name
+		}
+		if err := job.run(withLogger(ctx, log.Named(job.name))); err != nil {
+			return err
+		}
+	}
+}
+
+// next waits for the next task in the queue. It returns false if ctx closes or
+// the pool is closed and drained.
+func (p *Pool) next(ctx context.Context) (*subtask, bool) {
+	for {
+		p.mu.Lock()
+		if p.queue.Len() > 0 {
+			job := p.queue.Pop()
+			more := p.queue.Len() > 0
+			p.mu.Unlock()
+
+			// Pass the signal on to another worker if there is more work
+			if more {
+				notify(p.available)
+			}
+			notify(p.space)
+			return job, true
+		}
+		closed := p.closed
+		p.mu.Unlock()
+
+		if closed {
+			return nil, false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-p.available:
+		case <-p.closing:
+		}
+	}
+}
+
+// notify sends a signal to the channel of capacity 1 unless a signal is
+// already pending
+func notify(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
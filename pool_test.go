@@ -0,0 +1,148 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolDrain(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool(4, 10)
+
+	var done int32
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("pool", Exit, pool.Run)
+		for i := 0; i < 100; i++ {
+			if err := pool.Submit("task", func(ctx context.Context) error {
+				atomic.AddInt32(&done, 1)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		pool.Close()
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 100, done)
+	require.ErrorIs(t, pool.Submit("late", func(ctx context.Context) error {
+		return nil
+	}), ErrPoolClosed)
+}
+
+func TestPoolError(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool(2, 1)
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("pool", Fail, pool.Run)
+		spawn("submitter", Continue, func(ctx context.Context) error {
+			for {
+				err := pool.Submit("task", func(ctx context.Context) error {
+					return errors.New("oops")
+				})
+				if errors.Is(err, ErrPoolClosed) {
+					return nil
+				}
+			}
+		})
+		return nil
+	})
+	require.EqualError(t, err, "oops")
+}
+
+func TestPoolPriority(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool(1, 0)
+
+	var order []string
+	for _, p := range []struct {
+		name     string
+		priority int
+	}{{"low", -1}, {"default", 0}, {"high", 10}} {
+		name := p.name
+		require.NoError(t, pool.Submit(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}, WithPriority(p.priority)))
+	}
+	pool.Close()
+
+	require.NoError(t, pool.Run(ctx))
+	require.Equal(t, []string{"high", "default", "low"}, order)
+}
+
+func TestPoolTaskTimeout(t *testing.T) {
+	ctx := context.Background()
+	pool := NewPool(1, 1)
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("pool", Fail, pool.Run)
+		return pool.Submit("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithTaskTimeout(time.Millisecond))
+	})
+	require.EqualError(t, err, "task pool.slow timed out after 1ms: context deadline exceeded")
+}
+
+func TestPoolOverflowPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	var order []string
+	task := func(name string) Task {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	pool := NewPool(1, 2, WithOverflowPolicy(OverflowReject))
+	require.NoError(t, pool.Submit("a", task("a")))
+	require.NoError(t, pool.Submit("b", task("b")))
+	require.ErrorIs(t, pool.Submit("c", task("c")), ErrQueueFull)
+	pool.Close()
+	require.NoError(t, pool.Run(ctx))
+	require.Equal(t, []string{"a", "b"}, order)
+
+	order = nil
+	pool = NewPool(1, 2, WithOverflowPolicy(OverflowDropOldest))
+	require.NoError(t, pool.Submit("a", task("a")))
+	require.NoError(t, pool.Submit("b", task("b"), WithPriority(1)))
+	require.NoError(t, pool.Submit("c", task("c")))
+	require.NoError(t, pool.Submit("d", task("d")))
+	pool.Close()
+	require.NoError(t, pool.Run(ctx))
+	require.Equal(t, []string{"b", "d"}, order)
+}
+
+func BenchmarkPoolMixedDurations(b *testing.B) {
+	pool := NewPool(8, 1024)
+	g := NewGroup(context.Background(), WithLogger(NopLogger()))
+	g.Spawn("pool", Exit, pool.Run)
+
+	short := func(ctx context.Context) error {
+		return nil
+	}
+	long := func(ctx context.Context) error {
+		time.Sleep(100 * time.Microsecond)
+		return nil
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		task := short
+		if i%16 == 0 {
+			task = long
+		}
+		require.NoError(b, pool.Submit("task", task))
+	}
+	pool.Close()
+	require.NoError(b, g.Wait())
+}
@@ -0,0 +1,81 @@
+package parallel
+
+import (
+	"container/heap"
+	"math/rand"
+)
+
+// priorityQueue is a queue of items ordered by priority, higher first. Items
+// of equal priority are ordered by the time of pushing, or randomly if shuffle
+// is set.
+type priorityQueue[T any] struct {
+	items   queueItems[T]
+	seq     uint64
+	shuffle *rand.Rand
+}
+
+type queueItem[T any] struct {
+	value    T
+	priority int
+	seq      uint64
+}
+
+func (q *priorityQueue[T]) Len() int {
+	return len(q.items)
+}
+
+func (q *priorityQueue[T]) Push(value T, priority int) {
+	q.seq++
+	seq := q.seq
+	if q.shuffle != nil {
+		seq = q.shuffle.Uint64()
+	}
+	heap.Push(&q.items, queueItem[T]{value: value, priority: priority, seq: seq})
+}
+
+func (q *priorityQueue[T]) Pop() T {
+	return heap.Pop(&q.items).(queueItem[T]).value
+}
+
+// DropOldest removes the item of the lowest priority, the oldest of them if
+// there are many, and returns it
+func (q *priorityQueue[T]) DropOldest() T {
+	oldest := 0
+	for i, item := range q.items {
+		o := q.items[oldest]
+		if item.priority < o.priority || item.priority == o.priority && item.seq < o.seq {
+			oldest = i
+		}
+	}
+	return heap.Remove(&q.items, oldest).(queueItem[T]).value
+}
+
+// queueItems implements heap.Interface
+type queueItems[T any] []queueItem[T]
+
+func (qi queueItems[T]) Len() int {
+	return len(qi)
+}
+
+func (qi queueItems[T]) Less(i, j int) bool {
+	if qi[i].priority != qi[j].priority {
+		return qi[i].priority > qi[j].priority
+	}
+	return qi[i].seq < qi[j].seq
+}
+
+func (qi queueItems[T]) Swap(i, j int) {
+	qi[i], qi[j] = qi[j], qi[i]
+}
+
+func (qi *queueItems[T]) Push(x interface{}) {
+	*qi = append(*qi, x.(queueItem[T]))
+}
+
+func (qi *queueItems[T]) Pop() interface{} {
+	old := *qi
+	item := old[len(old)-1]
+	old[len(old)-1] = queueItem[T]{}
+	*qi = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,134 @@
+package parallel
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Race runs the tasks concurrently and returns nil as soon as one of them
+// succeeds, canceling the others. If all the tasks fail, their errors are
+// returned joined by errors.Join.
+//
+// A panic in any of the tasks is returned as PanicError.
+func Race(ctx context.Context, tasks ...Task) error {
+	fns := make([]func(ctx context.Context) (struct{}, error), 0, len(tasks))
+	for _, task := range tasks {
+		task := task
+		fns = append(fns, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, task(ctx)
+		})
+	}
+	_, err := RaceResult(ctx, fns...)
+	return err
+}
+
+// RaceResult runs the functions concurrently like Race does, and returns the
+// result of the first one to succeed
+func RaceResult[T any](ctx context.Context, fns ...func(ctx context.Context) (T, error)) (T, error) {
+	g := NewGroup(ctx)
+
+	var mu sync.Mutex
+	var result T
+	var won bool
+	errs := make([]error, len(fns))
+	for i, fn := range fns {
+		i, fn := i, fn
+		g.Spawn(fmt.Sprintf("racer-%d", i), Continue, func(ctx context.Context) error {
+			res, err := fn(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case won:
+			case err != nil:
+				errs[i] = err
+			default:
+				won = true
+				result = res
+				g.Exit(nil)
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		var zero T
+		return zero, err
+	}
+	if !won {
+		var zero T
+		return zero, stderrors.Join(errs...)
+	}
+	return result, nil
+}
+
+// Hedge calls fn and, if it hasn't succeeded within the delay, calls it again
+// concurrently, up to the given number of attempts in total. The next attempt
+// is also started as soon as a running one fails. The result of the first
+// attempt to succeed is returned and the other attempts are canceled. If all
+// the attempts fail, their errors are returned joined by errors.Join.
+//
+// Use it to cut tail latency of idempotent requests.
+func Hedge[T any](ctx context.Context, delay time.Duration, attempts int, fn func(ctx context.Context) (T, error)) (T, error) {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	g := NewGroup(ctx)
+
+	var mu sync.Mutex
+	var result T
+	var won bool
+	var errs []error
+	failed := make(chan struct{}, attempts)
+	attempt := func(ctx context.Context) error {
+		res, err := fn(ctx)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case won:
+		case err != nil:
+			errs = append(errs, err)
+			failed <- struct{}{}
+		default:
+			won = true
+			result = res
+			g.Exit(nil)
+		}
+		return nil
+	}
+
+	g.Spawn("hedger", Continue, func(ctx context.Context) error {
+		for i := 0; i < attempts; i++ {
+			if i > 0 {
+				timer := clockFromContext(ctx).NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil
+				case <-failed:
+					timer.Stop()
+				case <-timer.C():
+				}
+			}
+			g.Spawn(fmt.Sprintf("attempt-%d", i), Continue, attempt)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		var zero T
+		return zero, err
+	}
+	if !won {
+		var zero T
+		return zero, stderrors.Join(errs...)
+	}
+	return result, nil
+}
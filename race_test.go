@@ -0,0 +1,82 @@
+package parallel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRace(t *testing.T) {
+	ctx := context.Background()
+
+	canceled := make(chan error, 1)
+	err := Race(ctx,
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return ctx.Err()
+		},
+		func(ctx context.Context) error {
+			return errors.New("oops")
+		},
+		func(ctx context.Context) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.ErrorIs(t, <-canceled, context.Canceled)
+
+	err = Race(ctx,
+		func(ctx context.Context) error {
+			return errors.New("oops1")
+		},
+		func(ctx context.Context) error {
+			return errors.New("oops2")
+		},
+	)
+	require.EqualError(t, err, "oops1\noops2")
+}
+
+func TestRaceResult(t *testing.T) {
+	ctx := context.Background()
+
+	replica, err := RaceResult(ctx,
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "slow", ctx.Err()
+		},
+		func(ctx context.Context) (string, error) {
+			return "fast", nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "fast", replica)
+}
+
+func TestHedge(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	n, err := Hedge(ctx, 50*time.Millisecond, 3, func(ctx context.Context) (int32, error) {
+		call := atomic.AddInt32(&calls, 1)
+		if call == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return call, nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+	require.EqualValues(t, 2, calls)
+
+	calls = 0
+	_, err = Hedge(ctx, time.Hour, 3, func(ctx context.Context) (int32, error) {
+		return 0, errors.Errorf("oops%d", atomic.AddInt32(&calls, 1))
+	})
+	require.EqualError(t, err, "oops1\noops2\noops3")
+}
@@ -0,0 +1,79 @@
+package parallel
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Ready reports that the subtask running with ctx has finished initializing,
+// see Group.WaitReady. It does nothing if ctx doesn't belong to a subtask.
+func Ready(ctx context.Context) {
+	st, ok := ctx.Value(subtaskKey).(*subtask)
+	if !ok {
+		return
+	}
+
+	g := st.group
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !st.ready {
+		st.ready = true
+		g.notifySettled()
+	}
+}
+
+// WaitReady blocks until all the subtasks spawned so far have called Ready or
+// finished. If any of them returns an error before getting ready, WaitReady
+// returns it. If ctx closes first, ctx.Err() is returned. Attached subtasks
+// are not waited for, see Group.Attach.
+func (g *Group) WaitReady(ctx context.Context) error {
+	g.mu.Lock()
+	pending := make([]*subtask, 0, len(g.tasks))
+	for _, st := range g.sortedTasks(false) {
+		if !st.attached {
+			pending = append(pending, st)
+		}
+	}
+	g.mu.Unlock()
+
+	for {
+		g.mu.Lock()
+		remaining := pending[:0]
+		for _, st := range pending {
+			switch {
+			case st.state == TaskFinished && st.err != nil && !st.ready:
+				g.mu.Unlock()
+				return errors.WithMessagef(st.err, "task %s failed before getting ready", st.path)
+			case st.state != TaskFinished && !st.ready:
+				remaining = append(remaining, st)
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			g.mu.Unlock()
+			return nil
+		}
+		if g.settled == nil {
+			g.settled = make(chan struct{})
+		}
+		settled := g.settled
+		g.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-settled:
+		}
+	}
+}
+
+// notifySettled wakes up WaitReady and WaitN after a subtask has got ready or
+// finished. The mutex must be held.
+func (g *Group) notifySettled() {
+	if g.settled != nil {
+		close(g.settled)
+		g.settled = nil
+	}
+}
@@ -0,0 +1,47 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitReady(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	initialize := make(chan struct{})
+	g.Spawn("server", Fail, func(ctx context.Context) error {
+		<-initialize
+		Ready(ctx)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Spawn("job", Continue, func(ctx context.Context) error {
+		return nil
+	})
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, g.WaitReady(waitCtx), context.Canceled)
+
+	close(initialize)
+	require.NoError(t, g.WaitReady(ctx))
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestWaitReadyFailure(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.Spawn("server", Fail, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+
+	require.EqualError(t, g.WaitReady(ctx), "task server failed before getting ready: oops")
+	require.EqualError(t, g.Wait(), "oops")
+}
@@ -3,12 +3,112 @@ package parallel
 import (
 	"context"
 	"fmt"
-	"runtime/debug"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 
-	"github.com/outofforest/logger"
-	"go.uber.org/zap"
+	"github.com/pkg/errors"
 )
 
+// StackCapture configures how the stack is captured into PanicError when a
+// task panics
+type StackCapture struct {
+	// MaxSize limits the size of the captured stack in bytes, the stack is
+	// truncated beyond that. A non-positive value means no limit, which is the
+	// default.
+	MaxSize int
+
+	// AllGoroutines makes the stacks of all the goroutines captured, starting
+	// with the panicking one
+	AllGoroutines bool
+}
+
+// WithStackCapture configures how the stack is captured when a subtask of the
+// group or of its subgroups panics
+func WithStackCapture(c StackCapture) GroupOption {
+	return func(g *Group) {
+		g.stackCapture = &c
+	}
+}
+
+func (c StackCapture) capture() []byte {
+	size := 4096
+	for {
+		if c.MaxSize > 0 && size > c.MaxSize {
+			size = c.MaxSize
+		}
+		buf := make([]byte, size)
+		n := runtime.Stack(buf, c.AllGoroutines)
+		if n < size || size == c.MaxSize {
+			return buf[:n]
+		}
+		size *= 2
+	}
+}
+
+// stackCaptureFromContext returns the stack capture configuration of the group
+// owning ctx
+func stackCaptureFromContext(ctx context.Context) StackCapture {
+	if c, ok := ctx.Value(stackCaptureKey).(*StackCapture); ok {
+		return *c
+	}
+	return StackCapture{}
+}
+
+// OnPanicFunc is a hook called with the hierarchical name of the task, see
+// TaskInfo.Path, and the recovered panic whenever a task panics
+type OnPanicFunc func(name string, p PanicError)
+
+var (
+	defaultOnPanicMu sync.RWMutex
+	defaultOnPanic   OnPanicFunc
+)
+
+// SetDefaultOnPanic sets the hook called when a task panics in a group which
+// neither it nor its parent groups were created with WithOnPanic
+func SetDefaultOnPanic(fn OnPanicFunc) {
+	defaultOnPanicMu.Lock()
+	defer defaultOnPanicMu.Unlock()
+
+	defaultOnPanic = fn
+}
+
+// WithOnPanic sets the hook called when a subtask of the group or of its
+// subgroups panics. The hook is called in the goroutine of the subtask after
+// the panic is recovered.
+func WithOnPanic(fn OnPanicFunc) GroupOption {
+	return func(g *Group) {
+		g.onPanic = fn
+	}
+}
+
+// WithRepanic makes Wait panic with the PanicError, carrying the stack of the
+// original panic, if the group result is caused by a panic of a subtask,
+// including the ones in subgroups. This way a panic crashes the process the
+// same way as if it happened without the group.
+//
+// Other subtasks are still shut down before Wait panics.
+func WithRepanic() GroupOption {
+	return func(g *Group) {
+		g.repanic = true
+	}
+}
+
+// onPanicFromContext returns the panic hook of the group owning ctx, falling
+// back to the default one
+func onPanicFromContext(ctx context.Context) OnPanicFunc {
+	if fn, ok := ctx.Value(onPanicKey).(OnPanicFunc); ok {
+		return fn
+	}
+
+	defaultOnPanicMu.RLock()
+	defer defaultOnPanicMu.RUnlock()
+
+	return defaultOnPanic
+}
+
 // PanicError is the error type that occurs when a subtask panics
 type PanicError struct {
 	Value interface{}
@@ -19,6 +119,50 @@ func (err PanicError) Error() string {
 	return fmt.Sprintf("panic: %s", err.Value)
 }
 
+// Frame is a single function call in the stack of a panicking task
+type Frame struct {
+	Func string
+	File string
+	Line int
+}
+
+// Frames parses the stack of the panicking goroutine, starting from the
+// function which called panic, followed by its callers. If the stack is
+// truncated, so are the frames.
+func (err PanicError) Frames() []Frame {
+	stack, _, _ := strings.Cut(string(err.Stack), "\n\n")
+	lines := strings.Split(stack, "\n")
+
+	var frames []Frame
+	// The first line is the goroutine header
+	for i := 1; i+1 < len(lines); i += 2 {
+		fn := strings.TrimPrefix(lines[i], "created by ")
+		if j := strings.Index(fn, " in goroutine "); j >= 0 {
+			fn = fn[:j]
+		} else if j := strings.LastIndex(fn, "("); j > 0 {
+			fn = fn[:j]
+		}
+
+		location, _, _ := strings.Cut(strings.TrimPrefix(lines[i+1], "\t"), " +0x")
+		j := strings.LastIndex(location, ":")
+		if j < 0 {
+			break
+		}
+		line, err := strconv.Atoi(location[j+1:])
+		if err != nil {
+			break
+		}
+
+		if fn == "panic" {
+			// Frames of the recovery are skipped
+			frames = nil
+			continue
+		}
+		frames = append(frames, Frame{Func: fn, File: location[:j], Line: line})
+	}
+	return frames
+}
+
 // Unwrap returns the error passed to panic, or nil if panic was called with
 // something other than an error
 func (err PanicError) Unwrap() error {
@@ -30,13 +174,51 @@ func (err PanicError) Unwrap() error {
 
 // runTask executes the task in the current goroutine, recovering from panics.
 // A panic is returned as PanicError.
-func runTask(ctx context.Context, task Task) (err error) {
+func runTask(ctx context.Context, name string, task Task) (err error) {
 	defer func() {
 		if p := recover(); p != nil {
-			panicErr := PanicError{Value: p, Stack: debug.Stack()}
+			panicErr := PanicError{Value: p, Stack: stackCaptureFromContext(ctx).capture()}
 			err = panicErr
-			logger.Get(ctx).Error("Panic", zap.String("value", fmt.Sprint(p)), zap.ByteString("stack", panicErr.Stack))
+			loggerFromContext(ctx).Error("Panic", "value", fmt.Sprint(p), "stack", string(panicErr.Stack))
+			if onPanic := onPanicFromContext(ctx); onPanic != nil {
+				onPanic(name, panicErr)
+			}
+			if observer, ok := ctx.Value(observerKey).(Observer); ok {
+				observer(Event{
+					Type:   EventTaskPanicked,
+					Time:   clockFromContext(ctx).Now(),
+					Task:   name,
+					ID:     TaskID(ctx),
+					Labels: TaskLabels(ctx),
+					Group:  GroupName(ctx),
+					Err:    panicErr,
+				})
+			}
 		}
 	}()
 	return task(ctx)
 }
+
+// packageDir is the source directory of this package
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// isInternalPanic reports whether err is caused by a panic raised by the code
+// of this package rather than by the task. Such a panic is a bug which can't be
+// fixed by restarting the task.
+func isInternalPanic(err error) bool {
+	var panicErr PanicError
+	if !errors.As(err, &panicErr) {
+		return false
+	}
+	for _, frame := range panicErr.Frames() {
+		// Runtime errors are raised by the runtime on behalf of the caller
+		if strings.HasPrefix(frame.Func, "runtime.") {
+			continue
+		}
+		return filepath.Dir(frame.File) == packageDir && !strings.HasSuffix(frame.File, "_test.go")
+	}
+	return false
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"testing"
 
-	"github.com/outofforest/logger"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -14,7 +13,7 @@ func panicWith(value interface{}) error {
 }
 
 func TestPanicString(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
 		spawn("doomed", Fail, func(ctx context.Context) error {
 			return panicWith("oops")
@@ -30,7 +29,7 @@ func TestPanicString(t *testing.T) {
 }
 
 func TestPanicError(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
 		spawn("doomed", Fail, func(ctx context.Context) error {
 			return panicWith(errors.New("oops"))
@@ -44,3 +43,112 @@ func TestPanicError(t *testing.T) {
 	// not where the panic is collected
 	require.Regexp(t, "(?s)^goroutine.*panicWith", string(err.Stack))
 }
+
+func TestOnPanic(t *testing.T) {
+	ctx := context.Background()
+
+	var names []string
+	var values []interface{}
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		sub := NewSubgroup(spawn, "sub", Fail)
+		sub.Spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	}, WithOnPanic(func(name string, p PanicError) {
+		names = append(names, name)
+		values = append(values, p.Value)
+	}))
+	require.IsType(t, PanicError{}, err)
+	require.Equal(t, []string{"sub.doomed"}, names)
+	require.Equal(t, []interface{}{"oops"}, values)
+}
+
+func TestDefaultOnPanic(t *testing.T) {
+	ctx := context.Background()
+
+	var called bool
+	SetDefaultOnPanic(func(name string, p PanicError) {
+		called = true
+	})
+	defer SetDefaultOnPanic(nil)
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	})
+	require.IsType(t, PanicError{}, err)
+	require.True(t, called)
+}
+
+func TestRepanic(t *testing.T) {
+	ctx := context.Background()
+
+	defer func() {
+		p, ok := recover().(PanicError)
+		require.True(t, ok)
+		require.Equal(t, "oops", p.Value)
+		require.Regexp(t, "(?s)^goroutine.*panicWith", string(p.Stack))
+	}()
+
+	_ = Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		sub := NewSubgroup(spawn, "sub", Fail)
+		sub.Spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	}, WithRepanic())
+	t.Fatal("Run should have panicked")
+}
+
+func TestStackCapture(t *testing.T) {
+	ctx := context.Background()
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	}, WithStackCapture(StackCapture{MaxSize: 100})).(PanicError)
+	require.Len(t, err.Stack, 100)
+
+	err = Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	}, WithStackCapture(StackCapture{AllGoroutines: true})).(PanicError)
+	require.Regexp(t, "(?s)^goroutine.*panicWith.*TestStackCapture", string(err.Stack))
+}
+
+func TestPanicFrames(t *testing.T) {
+	ctx := context.Background()
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("doomed", Fail, func(ctx context.Context) error {
+			return panicWith("oops")
+		})
+		return nil
+	}).(PanicError)
+
+	frames := err.Frames()
+	require.NotEmpty(t, frames)
+	require.Equal(t, "github.com/outofforest/parallel.panicWith", frames[0].Func)
+	require.Regexp(t, "/recover_test.go$", frames[0].File)
+	require.Equal(t, 12, frames[0].Line)
+	require.Equal(t, "github.com/outofforest/parallel.(*Group).start", frames[len(frames)-1].Func)
+}
+
+func TestInternalPanic(t *testing.T) {
+	stack := func(file string) []byte {
+		return []byte("goroutine 1 [running]:\n" +
+			"panic({0x1, 0x2})\n\t/go/src/runtime/panic.go:1 +0x1\n" +
+			"runtime.closechan(0x1)\n\t/go/src/runtime/chan.go:1 +0x1\n" +
+			"github.com/outofforest/parallel.f()\n\t" + file + ":10 +0x1\n")
+	}
+	require.True(t, isInternalPanic(errors.Wrap(PanicError{Stack: stack(packageDir + "/future.go")}, "wrapped")))
+	require.False(t, isInternalPanic(PanicError{Stack: stack(packageDir + "/future_test.go")}))
+	require.False(t, isInternalPanic(PanicError{Stack: stack("/elsewhere/main.go")}))
+	require.False(t, isInternalPanic(errors.New("oops")))
+}
@@ -0,0 +1,129 @@
+package parallel
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTaskRestarted is the cancellation cause of the context of a subtask
+// restarted on demand, see Group.Restart and WithReload
+var ErrTaskRestarted = errors.New("task restarted")
+
+// ErrTaskNotRunning is returned by Group.Restart if no subtask of the given
+// name is running
+var ErrTaskNotRunning = errors.New("task not running")
+
+// Restart restarts the running subtasks of the given name, e.g. on request of
+// an admin endpoint. The context of the subtask is canceled with
+// ErrTaskRestarted as the cause. Once the subtask returns, it's started again
+// right away with the same OnExit mode, regardless of the result, which is
+// discarded. Restart waits for the subtask to return.
+//
+// The subtask running a subgroup created by NewSubgroup or attached by
+// Reattach is not restarted itself. The running subtasks of the subgroup are
+// restarted instead.
+//
+// If the group is shutting down, the subtask is not started again.
+// ErrTaskNotRunning is returned if no subtask of the name is running, which
+// includes queued subtasks and subtasks waiting to be restarted in Restart
+// mode.
+func (g *Group) Restart(name string) error {
+	stopped, found := g.restartTasks(func(n string) bool {
+		return n == name
+	})
+	if !found {
+		return errors.WithMessagef(ErrTaskNotRunning, "restarting task %s", g.taskPath(name))
+	}
+	for _, ch := range stopped {
+		<-ch
+	}
+	return nil
+}
+
+// WithReload makes the group restart its running subtasks of the given names
+// when the process receives the signal, e.g. to reload the configuration on
+// SIGHUP without restarting the process. A subtask running a subgroup is
+// restarted by restarting the subtasks of the subgroup.
+//
+// The subtasks are restarted the same way as by Group.Restart. Queued
+// subtasks and subtasks waiting to be restarted in Restart mode are not
+// affected.
+//
+// The signal is handled until the group context closes or Wait returns.
+func WithReload(sig os.Signal, names ...string) GroupOption {
+	return func(g *Group) {
+		g.reloadSignal = sig
+		g.reloadTasks = names
+	}
+}
+
+// watchReload restarts the subtasks on the signal configured by WithReload
+func (g *Group) watchReload() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, g.reloadSignal)
+
+	stop := make(chan struct{})
+	var once sync.Once
+	g.stopReload = func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(stop)
+		})
+	}
+
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				g.log.Debug("Signal received, restarting tasks", "signal", sig.String(), "tasks", g.reloadTasks)
+				g.restartTasks(func(name string) bool {
+					return slices.Contains(g.reloadTasks, name)
+				})
+			case <-g.ctx.Done():
+				g.stopReload()
+				return
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// restartTasks cancels the current runs of the running subtasks matching the
+// names, so they are started again. It returns the channels closed when the
+// canceled runs return, and whether any subtask has matched.
+func (g *Group) restartTasks(match func(name string) bool) (stopped []chan struct{}, found bool) {
+	var owned []*Group
+	g.mu.Lock()
+	for _, st := range g.tasks {
+		if st.rerun == nil || !match(st.name) {
+			continue
+		}
+		found = true
+		if st.owned != nil {
+			owned = append(owned, st.owned)
+			continue
+		}
+		if !st.reloading {
+			st.reloading = true
+			st.stopped = make(chan struct{})
+			st.rerun(ErrTaskRestarted)
+		}
+		stopped = append(stopped, st.stopped)
+	}
+	g.mu.Unlock()
+
+	// The subtask running a subgroup can't be run again, because the subtasks
+	// of the subgroup were spawned by its caller, so they are restarted instead
+	for _, sub := range owned {
+		subStopped, _ := sub.restartTasks(func(string) bool {
+			return true
+		})
+		stopped = append(stopped, subStopped...)
+	}
+	return stopped, found
+}
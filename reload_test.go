@@ -0,0 +1,115 @@
+package parallel
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupReload(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithReload(syscall.SIGHUP, "config"))
+
+	runs := make(chan error, 2)
+	g.Spawn("config", Fail, func(ctx context.Context) error {
+		runs <- nil
+		<-ctx.Done()
+		runs <- context.Cause(ctx)
+		return ctx.Err()
+	})
+	var otherRuns int
+	g.Spawn("other", Fail, func(ctx context.Context) error {
+		otherRuns++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, <-runs)
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGHUP))
+	require.ErrorIs(t, <-runs, ErrTaskRestarted)
+	require.NoError(t, <-runs)
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.Equal(t, 1, otherRuns)
+	require.Equal(t, 1, g.Tasks()[0].Restarts)
+}
+
+func TestGroupRestartTask(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	started := make(chan struct{}, 2)
+	var runs int
+	g.Spawn("indexer", Exit, func(ctx context.Context) error {
+		runs++
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	require.ErrorIs(t, g.Restart("missing"), ErrTaskNotRunning)
+	require.NoError(t, g.Restart("indexer"))
+	<-started
+	require.NoError(t, g.Context().Err())
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.Equal(t, 2, runs)
+	require.ErrorIs(t, g.Restart("indexer"), ErrTaskNotRunning)
+}
+
+func TestGroupReloadSubgroup(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithReload(syscall.SIGUSR1, "updater"))
+
+	runs := make(chan error, 2)
+	subgroup := NewSubgroup(g.Spawn, "updater", Fail)
+	subgroup.Spawn("fetcher", Fail, func(ctx context.Context) error {
+		runs <- nil
+		<-ctx.Done()
+		runs <- context.Cause(ctx)
+		return ctx.Err()
+	})
+	require.NoError(t, <-runs)
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGUSR1))
+	require.ErrorIs(t, <-runs, ErrTaskRestarted)
+	require.NoError(t, <-runs)
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.Equal(t, 1, subgroup.Tasks()[0].Restarts)
+}
+
+func TestGroupRestartSubgroup(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	started := make(chan struct{}, 2)
+	var runs int
+	subgroup := NewSubgroup(g.Spawn, "updater", Fail)
+	subgroup.Spawn("fetcher", Fail, func(ctx context.Context) error {
+		runs++
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	require.NoError(t, g.Restart("updater"))
+	<-started
+	require.NoError(t, subgroup.Context().Err())
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	require.Equal(t, 2, runs)
+}
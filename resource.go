@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/pkg/errors"
+)
+
+// SpawnWithResource spawns a subtask which acquires a resource, like a file or
+// a connection, runs with it and releases it. The release function returned by
+// acquire is called after run returns, even if it panics, and its error is
+// joined with the one returned by run.
+//
+// If acquire fails, run is not called and the error is returned by the
+// subtask.
+func SpawnWithResource[T any](spawn SpawnFn, name string, onExit OnExit,
+	acquire func(ctx context.Context) (T, func() error, error), run func(ctx context.Context, resource T) error,
+) {
+	spawn(name, onExit, func(ctx context.Context) (err error) {
+		resource, release, err := acquire(ctx)
+		if err != nil {
+			return errors.WithMessage(err, "acquiring resource")
+		}
+		defer func() {
+			if relErr := release(); relErr != nil {
+				err = stderrors.Join(err, errors.WithMessage(relErr, "releasing resource"))
+			}
+		}()
+		return run(ctx, resource)
+	})
+}
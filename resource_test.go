@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpawnWithResource(t *testing.T) {
+	ctx := context.Background()
+
+	var released bool
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		SpawnWithResource(spawn, "task", Exit, func(ctx context.Context) (string, func() error, error) {
+			return "conn", func() error {
+				released = true
+				return errors.New("close failed")
+			}, nil
+		}, func(ctx context.Context, conn string) error {
+			if conn != "conn" {
+				return errors.New("wrong resource")
+			}
+			return errors.New("oops")
+		})
+		return nil
+	})
+	require.True(t, released)
+	require.EqualError(t, err, "oops\nreleasing resource: close failed")
+}
+
+func TestSpawnWithResourceAcquireFailed(t *testing.T) {
+	ctx := context.Background()
+
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		SpawnWithResource(spawn, "task", Exit, func(ctx context.Context) (int, func() error, error) {
+			return 0, nil, errors.New("oops")
+		}, func(ctx context.Context, resource int) error {
+			return errors.New("run called")
+		})
+		return nil
+	})
+	require.EqualError(t, err, "acquiring resource: oops")
+}
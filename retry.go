@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"context"
+)
+
+// RetryPolicy configures Retry
+type RetryPolicy struct {
+	// Backoff configures the delays between attempts, DefaultBackoff is used if
+	// it's zero
+	Backoff Backoff
+
+	// MaxAttempts is the maximum number of attempts, zero means unlimited
+	MaxAttempts int
+
+	// Retriable reports whether the error is worth retrying. If it's nil, all
+	// errors are retried except those marked by Permanent.
+	Retriable func(err error) bool
+}
+
+// Retry runs the task until it succeeds, waiting between attempts as
+// configured by the policy. The error of the last attempt is returned if the
+// attempts are exhausted, if the error is not retriable, or if ctx closes.
+func Retry(ctx context.Context, policy RetryPolicy, task Task) error {
+	backoff := policy.Backoff
+	if backoff == (Backoff{}) {
+		backoff = DefaultBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := task(ctx)
+		if err == nil || ctx.Err() != nil || IsPermanent(err) {
+			return err
+		}
+		if policy.Retriable != nil && !policy.Retriable(err) {
+			return err
+		}
+		if policy.MaxAttempts > 0 && attempt+1 >= policy.MaxAttempts {
+			return err
+		}
+
+		delay := backoff.Delay(attempt)
+		loggerFromContext(ctx).Debug("Attempt failed, retrying", "error", err, "attempt", attempt+1, "delay", delay)
+
+		timer := clockFromContext(ctx).NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C():
+		}
+	}
+}
+
+// WithRetry makes the subtask retry failed runs according to the policy, see
+// Retry. Unlike Restart mode, the subtask is not started again once it
+// succeeds, and the error of the last attempt is handled according to the
+// OnExit mode.
+func WithRetry(policy RetryPolicy) SpawnOption {
+	return func(st *subtask) {
+		task := st.task
+		st.task = func(ctx context.Context) error {
+			return Retry(ctx, policy, task)
+		}
+	}
+}
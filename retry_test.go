@@ -0,0 +1,82 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryBackoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int
+	err := Retry(ctx, RetryPolicy{Backoff: testRetryBackoff}, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("oops")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = Retry(ctx, RetryPolicy{Backoff: testRetryBackoff, MaxAttempts: 2}, func(ctx context.Context) error {
+		attempts++
+		return errors.Errorf("oops%d", attempts)
+	})
+	require.EqualError(t, err, "oops2")
+	require.Equal(t, 2, attempts)
+
+	fatal := errors.New("fatal")
+	attempts = 0
+	err = Retry(ctx, RetryPolicy{
+		Backoff: testRetryBackoff,
+		Retriable: func(err error) bool {
+			return !errors.Is(err, fatal)
+		},
+	}, func(ctx context.Context) error {
+		attempts++
+		if attempts == 2 {
+			return fatal
+		}
+		return errors.New("oops")
+	})
+	require.ErrorIs(t, err, fatal)
+	require.Equal(t, 2, attempts)
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+
+	var attempts int
+	err := Retry(ctx, RetryPolicy{Backoff: Backoff{Initial: time.Hour, Max: time.Hour}}, func(ctx context.Context) error {
+		attempts++
+		cancel()
+		return errors.New("oops")
+	})
+	require.EqualError(t, err, "oops")
+	require.Equal(t, 1, attempts)
+}
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	var attempts int
+	g.SpawnWith("task", Exit, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("oops")
+		}
+		return nil
+	}, WithRetry(RetryPolicy{Backoff: testRetryBackoff}))
+	require.NoError(t, g.Wait())
+	require.Equal(t, 3, attempts)
+}
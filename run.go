@@ -3,6 +3,9 @@ package parallel
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 // SpawnFn is a function that starts a subtask in a goroutine.
@@ -12,13 +15,28 @@ import (
 //
 // The onExit mode specifies what happens if the subtask exits, see
 // documentation for OnExit.
+//
+// To configure the subtask with SpawnOption, use Group.SpawnWith.
 type SpawnFn func(name string, onExit OnExit, task Task)
 
+// Spawn calls the function, so SpawnFn implements Spawner
+func (spawn SpawnFn) Spawn(name string, onExit OnExit, task Task) {
+	spawn(name, onExit, task)
+}
+
+// Spawner starts subtasks. It is implemented by Group and SpawnFn, so code
+// spawning subtasks may depend on it and get a fake in tests.
+type Spawner interface {
+	Spawn(name string, onExit OnExit, task Task)
+}
+
 // OnExit is an enumeration of exit handling modes. It specifies what should
-// happen to the parent task if the subtask returns nil.
+// happen to the parent task if the subtask returns.
 //
-// Regardless of the chosen mode, if the subtask returns an error, it causes the
-// parent task to shut down gracefully and return that error.
+// Unless the mode is Restart or OnError, if the subtask returns an error, it
+// causes the parent task to shut down gracefully and return that error. The
+// exceptions are errors tolerated by the group, see WithMaxErrors, and errors
+// ignored by the mode chosen by WithOnExitFunc.
 type OnExit int
 
 const (
@@ -49,6 +67,38 @@ const (
 	// Use this mode for subtasks that should never return unless their context
 	// is closed.
 	Fail
+
+	// Restart means run the subtask again after a delay growing exponentially
+	// with consecutive failures, see WithRestartBackoff. The subtask is
+	// restarted both when it returns nil and when it returns an error, unless
+	// the error is marked by Permanent or is a panic raised by this package
+	// itself. Errors causing a restart are logged but don't affect the parent
+	// task.
+	//
+	// Use this mode for subtasks that should keep running despite transient
+	// failures, such as a connection to an external service.
+	//
+	// The subtask running a subgroup created by NewSubgroup can't be run
+	// again, so its second run fails with a permanent error. Restart the
+	// subtasks of the subgroup instead.
+	Restart
+
+	// OnError means the same as Continue, except that an error returned by the
+	// subtask is only logged instead of shutting down the parent task. The
+	// error is reported by Group.Tasks as TaskInfo.Err.
+	//
+	// Use this mode for jobs whose failures are expected and shouldn't affect
+	// other jobs, such as processing of a single item by a crawler.
+	OnError
+
+	// Crash means the same as Fail, except that the process is terminated
+	// immediately instead of shutting down the parent task gracefully, see
+	// SetFatalHandler. The subtask exiting because the parent task is shutting
+	// down doesn't terminate the process.
+	//
+	// Use this mode for subtasks whose failure leaves the process in a state
+	// in which graceful shutdown would do more harm than good.
+	Crash
 )
 
 func (onExit OnExit) String() string {
@@ -59,11 +109,34 @@ func (onExit OnExit) String() string {
 		return "Exit"
 	case Fail:
 		return "Fail"
+	case Restart:
+		return "Restart"
+	case OnError:
+		return "OnError"
+	case Crash:
+		return "Crash"
 	default:
 		return fmt.Sprintf("invalid OnExit mode: %d", onExit)
 	}
 }
 
+// OnExitFunc decides how to handle the result of a run of a subtask, given its
+// hierarchical name and the error returned, see WithOnExitFunc
+type OnExitFunc func(name string, err error) OnExit
+
+// WithOnExitFunc makes the subtask choose its OnExit mode after every run,
+// based on the result, instead of using the mode passed to spawn.
+//
+// The chosen mode is applied to the result as usual, except that an error is
+// not propagated to the group if Continue or Exit is chosen. Such an error is
+// logged and reported by Group.Tasks as TaskInfo.LastErr. If Restart is
+// chosen, the subtask is restarted as in Restart mode.
+func WithOnExitFunc(fn OnExitFunc) SpawnOption {
+	return func(st *subtask) {
+		st.onExitFunc = fn
+	}
+}
+
 // Run runs a task with several subtasks.
 //
 // The start function is the start-up sequence of the task. It receives a spawn
@@ -84,6 +157,8 @@ func (onExit OnExit) String() string {
 // The subtasks can in turn be implemented using parallel.Run and have subtasks
 // of their own.
 //
+// The group running the subtasks is configured by opts, see NewGroup.
+//
 // Example:
 //
 //	err := parallel.Run(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
@@ -105,8 +180,8 @@ func (onExit OnExit) String() string {
 //	    spawn("service2", parallel.Fail, s2.Run)
 //	    return nil
 //	})
-func Run(ctx context.Context, start func(ctx context.Context, spawn SpawnFn) error) error {
-	g := NewGroup(ctx)
+func Run(ctx context.Context, start func(ctx context.Context, spawn SpawnFn) error, opts ...GroupOption) error {
+	g := NewGroup(ctx, opts...)
 
 	if err := start(g.Context(), g.Spawn); err != nil {
 		g.Exit(err)
@@ -114,3 +189,62 @@ func Run(ctx context.Context, start func(ctx context.Context, spawn SpawnFn) err
 
 	return g.Wait()
 }
+
+// RunResult runs a task like Run does, and returns the value returned by start
+// once all the subtasks have finished. The value may thus be filled by the
+// subtasks, e.g. a slice with an element set by each of them. If the task
+// fails, the zero value is returned along with the error.
+func RunResult[T any](ctx context.Context, start func(ctx context.Context, spawn SpawnFn) (T, error), opts ...GroupOption) (T, error) {
+	var result T
+	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		var err error
+		result, err = start(ctx, spawn)
+		return err
+	}, opts...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// TimeoutError is returned by RunWithTimeout if the timeout expires before the
+// task finishes, and by Wait of a group created with WithTimeout or
+// WithDeadline if the group outlives it
+type TimeoutError struct {
+	// Timeout is the timeout which has expired
+	Timeout time.Duration
+}
+
+func (err TimeoutError) Error() string {
+	return fmt.Sprintf("group timed out after %s", err.Timeout)
+}
+
+// Unwrap makes TimeoutError match context.DeadlineExceeded
+func (err TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// RunWithTimeout runs a task like Run does, limiting its duration by the
+// timeout.
+//
+// If the task is shut down because the timeout expires, TimeoutError is
+// returned regardless of the results of the subtasks, so the expiry is
+// distinguished from the closing of ctx. If the task has been shutting down
+// for another reason when the timeout expires, its result is returned.
+func RunWithTimeout(ctx context.Context, timeout time.Duration, start func(ctx context.Context, spawn SpawnFn) error, opts ...GroupOption) error {
+	runCtx, cancel := withTimeout(ctx, clockFromContext(ctx), timeout)
+	defer cancel()
+
+	g := NewGroup(runCtx, opts...)
+	if err := start(g.Context(), g.Spawn); err != nil {
+		g.Exit(err)
+	}
+	err := g.Wait()
+
+	// A slow shutdown doesn't hide the error which has caused it
+	if ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) && errors.Is(g.Cause(), context.DeadlineExceeded) {
+		return errors.WithStack(TimeoutError{Timeout: timeout})
+	}
+	return err
+}
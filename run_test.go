@@ -2,15 +2,16 @@ package parallel
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/outofforest/logger"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRunNoSubtasksSuccess(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
 		return nil
 	})
@@ -18,7 +19,7 @@ func TestRunNoSubtasksSuccess(t *testing.T) {
 }
 
 func TestRunNoSubtasksError(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	err := Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
 		return errors.New("oops")
 	})
@@ -26,7 +27,7 @@ func TestRunNoSubtasksError(t *testing.T) {
 }
 
 func TestRunSubtaskExit(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -58,7 +59,7 @@ func TestRunSubtaskExit(t *testing.T) {
 }
 
 func TestRunSubtaskContinue(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -91,7 +92,7 @@ func TestRunSubtaskContinue(t *testing.T) {
 
 // Fail is the actual enum for handling mode, so it should be present
 func TestRunSubtaskFail(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -126,7 +127,7 @@ func TestRunSubtaskFail(t *testing.T) {
 }
 
 func TestRunSubtaskError(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -161,7 +162,7 @@ func TestRunSubtaskError(t *testing.T) {
 }
 
 func TestRunSubtaskInitError(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -191,7 +192,7 @@ func TestRunSubtaskInitError(t *testing.T) {
 }
 
 func TestRunShutdownNotOK(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -222,7 +223,7 @@ func TestRunShutdownNotOK(t *testing.T) {
 }
 
 func TestRunShutdownCancel(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	step1 := make(chan struct{})
 	step2 := make(chan struct{})
@@ -253,7 +254,7 @@ func TestRunShutdownCancel(t *testing.T) {
 }
 
 func TestRunCancel(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	var err error
 	go func() {
@@ -273,7 +274,7 @@ func TestRunCancel(t *testing.T) {
 
 // Fail is the actual way for handling the tasks, so it should be present
 func TestExitFailTaskOnCancel(t *testing.T) {
-	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	ctx := context.Background()
 	seq := make(chan int)
 	var err error
 	go func() {
@@ -296,3 +297,170 @@ func TestExitFailTaskOnCancel(t *testing.T) {
 	require.Equal(t, 3, <-seq)
 	require.NoError(t, err)
 }
+
+func TestRunWithTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	err := RunWithTimeout(ctx, time.Millisecond, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("slow", Fail, func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		return nil
+	})
+	require.EqualError(t, err, "group timed out after 1ms")
+	require.ErrorAs(t, err, new(TimeoutError))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = RunWithTimeout(ctx, time.Hour, func(ctx context.Context, spawn SpawnFn) error {
+		return errors.New("oops")
+	})
+	require.EqualError(t, err, "oops")
+
+	err = RunWithTimeout(ctx, 10*time.Millisecond, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("failing", Fail, func(ctx context.Context) error {
+			return errors.New("oops")
+		})
+		spawn("slow", Continue, func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+		return nil
+	})
+	require.EqualError(t, err, "oops")
+
+	parentCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = RunWithTimeout(parentCtx, time.Hour, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("task", Fail, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, errors.As(err, new(TimeoutError)))
+}
+
+func TestRunResult(t *testing.T) {
+	ctx := context.Background()
+
+	squares, err := RunResult(ctx, func(ctx context.Context, spawn SpawnFn) ([]int, error) {
+		squares := make([]int, 4)
+		for i := range squares {
+			i := i
+			spawn("square", Continue, func(ctx context.Context) error {
+				squares[i] = i * i
+				return nil
+			})
+		}
+		return squares, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 4, 9}, squares)
+
+	n, err := RunResult(ctx, func(ctx context.Context, spawn SpawnFn) (int, error) {
+		spawn("failing", Continue, func(ctx context.Context) error {
+			return errors.New("oops")
+		})
+		return 42, nil
+	})
+	require.EqualError(t, err, "oops")
+	require.Zero(t, n)
+}
+
+func TestOnExitFunc(t *testing.T) {
+	ctx := context.Background()
+
+	errRetry := errors.New("retry")
+	errIgnore := errors.New("ignore")
+	var runs int
+	var names []string
+	decide := func(name string, err error) OnExit {
+		names = append(names, name)
+		switch {
+		case errors.Is(err, errRetry):
+			return Restart
+		case errors.Is(err, errIgnore):
+			return Continue
+		default:
+			return Fail
+		}
+	}
+
+	g := NewGroup(ctx, WithRestartBackoff(Backoff{Initial: time.Millisecond, Max: time.Millisecond}))
+	g.SpawnWith("task", Fail, func(ctx context.Context) error {
+		runs++
+		if runs == 1 {
+			return errRetry
+		}
+		return errIgnore
+	}, WithOnExitFunc(decide))
+	require.NoError(t, g.Wait())
+	require.Equal(t, 2, runs)
+	require.Equal(t, []string{"task", "task"}, names)
+
+	tasks := g.Tasks()
+	require.Equal(t, Continue, tasks[0].OnExit)
+	require.ErrorIs(t, tasks[0].LastErr, errIgnore)
+
+	g = NewGroup(ctx)
+	g.SpawnWith("task", Continue, func(ctx context.Context) error {
+		return nil
+	}, WithOnExitFunc(decide))
+	require.EqualError(t, g.Wait(), "task task terminated unexpectedly")
+}
+
+func TestRunOnError(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.Spawn("failing", OnError, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	g.Spawn("ok", OnError, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+
+	tasks := g.Tasks()
+	require.Len(t, tasks, 2)
+	require.EqualError(t, tasks[0].Err, "oops")
+	require.NoError(t, tasks[1].Err)
+	require.Equal(t, "OnError", tasks[0].OnExit.String())
+}
+
+type inlineSpawner struct {
+	errs []error
+}
+
+func (s *inlineSpawner) Spawn(name string, onExit OnExit, task Task) {
+	s.errs = append(s.errs, task(context.Background()))
+}
+
+func TestSpawner(t *testing.T) {
+	ctx := context.Background()
+
+	var runs atomic.Int32
+	start := func(s Spawner) {
+		s.Spawn("task", Exit, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+	}
+
+	require.NoError(t, Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		start(spawn)
+		return nil
+	}))
+
+	g := NewGroup(ctx)
+	start(g)
+	require.NoError(t, g.Wait())
+
+	fake := &inlineSpawner{}
+	start(fake)
+	require.Equal(t, []error{nil}, fake.errs)
+	require.EqualValues(t, 3, runs.Load())
+}
@@ -0,0 +1,51 @@
+// Package servers provides tasks running network servers within the lifecycle
+// of parallel groups.
+package servers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/parallel"
+)
+
+// HTTPServerTask returns a task running the HTTP server. When the context of
+// the task closes, the server is shut down gracefully, waiting for the active
+// requests at most for shutdownTimeout, after which the remaining connections
+// are closed.
+//
+// The task returns ctx.Err() after the shutdown, nil if the server has been
+// shut down by someone else, or the error which stopped the server.
+func HTTPServerTask(srv *http.Server, shutdownTimeout time.Duration) parallel.Task {
+	return func(ctx context.Context) error {
+		served := make(chan error, 1)
+		go func() {
+			served <- srv.ListenAndServe()
+		}()
+
+		select {
+		case err := <-served:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return errors.WithStack(err)
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			_ = srv.Close()
+			<-served
+			return errors.Wrap(err, "shutting down HTTP server")
+		}
+		if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+			return errors.WithStack(err)
+		}
+		return ctx.Err()
+	}
+}
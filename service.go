@@ -0,0 +1,46 @@
+package parallel
+
+import "context"
+
+// Service is a long-running component, like a server, run as a subtask by
+// Group.SpawnService
+type Service interface {
+	// Run runs the service until ctx closes, see Task
+	Run(ctx context.Context) error
+}
+
+// ReadyService is a Service reporting when it has finished initializing
+type ReadyService interface {
+	Service
+
+	// Ready returns a channel which is closed when the service is ready
+	Ready() <-chan struct{}
+}
+
+// ServiceTask returns a task running the service. If the service implements
+// ReadyService, the task calls Ready once the service gets ready, so the
+// group reports it by Group.WaitReady.
+func ServiceTask(svc Service) Task {
+	rs, ok := svc.(ReadyService)
+	if !ok {
+		return svc.Run
+	}
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			select {
+			case <-rs.Ready():
+				Ready(ctx)
+			case <-done:
+			}
+		}()
+		return rs.Run(ctx)
+	}
+}
+
+// SpawnService spawns a subtask running the service, see ServiceTask
+func (g *Group) SpawnService(name string, onExit OnExit, svc Service, opts ...SpawnOption) {
+	g.SpawnWith(name, onExit, ServiceTask(svc), opts...)
+}
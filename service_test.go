@@ -0,0 +1,33 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testService struct {
+	ready chan struct{}
+}
+
+func (s *testService) Run(ctx context.Context) error {
+	close(s.ready)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (s *testService) Ready() <-chan struct{} {
+	return s.ready
+}
+
+func TestSpawnService(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.SpawnService("service", Fail, &testService{ready: make(chan struct{})})
+	require.NoError(t, g.WaitReady(ctx))
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
@@ -0,0 +1,188 @@
+package parallel
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StuckTask describes a subtask which hasn't finished in time during shutdown
+type StuckTask struct {
+	// Name is the hierarchical name of the subtask, see TaskInfo.Path
+	Name string
+
+	// ID is the unique ID of the subtask
+	ID int64
+
+	// Labels are the labels of the subtask, see WithLabels
+	Labels map[string]string
+
+	// Stack is the stack trace of the goroutine running the subtask, empty if
+	// the subtask is still queued
+	Stack string
+}
+
+// ShutdownTimeoutError is returned by ExitWithGrace if some subtasks haven't
+// finished within the grace period
+type ShutdownTimeoutError struct {
+	// Grace is the grace period which has expired
+	Grace time.Duration
+
+	// Tasks are the subtasks still running when the grace period expired
+	Tasks []StuckTask
+}
+
+func (err ShutdownTimeoutError) Error() string {
+	names := make([]string, 0, len(err.Tasks))
+	for _, t := range err.Tasks {
+		names = append(names, t.Name)
+	}
+	return fmt.Sprintf("shutdown timed out after %s, tasks still running: %s", err.Grace, strings.Join(names, ", "))
+}
+
+// ExitWithGrace prompts the group to shut down like Exit does, then waits for
+// the subtasks to finish, at most for the grace period.
+//
+// If all the subtasks finish in time, the group result is returned. Otherwise,
+// ShutdownTimeoutError listing the subtasks still running is returned. The
+// group keeps shutting down in that case, and Wait may be used to wait for it
+// further.
+func (g *Group) ExitWithGrace(err error, grace time.Duration) error {
+	g.Exit(err)
+
+	timer := g.clock.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-g.Done():
+		return g.Wait()
+	case <-timer.C():
+	}
+
+	stuck := g.unfinishedTasks()
+	if len(stuck) == 0 {
+		return g.Wait()
+	}
+	return ShutdownTimeoutError{Grace: grace, Tasks: stuck}
+}
+
+// WithWatchdog makes the group report subtasks which haven't finished within
+// the given time after the group context has been closed. Each of them is
+// logged with its stack trace and reported as EventTaskStuck.
+func WithWatchdog(after time.Duration) GroupOption {
+	return func(g *Group) {
+		g.watchdog = after
+	}
+}
+
+// watch reports the subtasks still running when the watchdog timer expires
+func (g *Group) watch() {
+	timer := g.clock.NewTimer(g.watchdog)
+	defer timer.Stop()
+
+	select {
+	case <-g.Done():
+		return
+	case <-timer.C():
+	}
+
+	for _, t := range g.unfinishedTasks() {
+		g.log.Error("Task stuck in shutdown", "task", t.Name, "id", hexID(t.ID), "after", g.watchdog, "stack", t.Stack)
+		g.observe(Event{Type: EventTaskStuck, Task: t.Name, ID: t.ID, Labels: t.Labels, Stack: t.Stack})
+	}
+}
+
+// Unfinished returns the subtasks of the group and of its subgroups which
+// haven't finished yet, with their stack traces
+func (g *Group) Unfinished() []StuckTask {
+	return withStacks(g.pendingTasks(true))
+}
+
+// unfinishedTasks returns the subtasks which haven't finished yet, with their
+// stack traces
+func (g *Group) unfinishedTasks() []StuckTask {
+	return withStacks(g.pendingTasks(false))
+}
+
+// pendingTasks returns the subtasks which haven't finished yet, optionally
+// with the ones of subgroups, without stack traces, along with their keys
+// identifying them in stack traces
+func (g *Group) pendingTasks(recursive bool) ([]StuckTask, []int64) {
+	var stuck []StuckTask
+	var keys []int64
+	var subgroups []*Group
+	g.mu.Lock()
+	for _, st := range g.sortedTasks(false) {
+		stuck = append(stuck, StuckTask{Name: st.path, ID: st.id, Labels: st.labels})
+		keys = append(keys, st.key)
+		if recursive {
+			subgroups = append(subgroups, st.subgroups...)
+		}
+	}
+	g.mu.Unlock()
+
+	// Subgroups are queried without holding the lock of the parent group
+	for _, sub := range subgroups {
+		subStuck, subKeys := sub.pendingTasks(true)
+		stuck = append(stuck, subStuck...)
+		keys = append(keys, subKeys...)
+	}
+	return stuck, keys
+}
+
+// withStacks fills in stack traces of the subtasks with the given keys
+func withStacks(stuck []StuckTask, keys []int64) []StuckTask {
+	if len(stuck) == 0 {
+		return nil
+	}
+
+	wanted := make(map[int64]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	stacks := taskStacks(wanted)
+	for i := range stuck {
+		stuck[i].Stack = stacks[keys[i]]
+	}
+	return stuck
+}
+
+// taskStacks returns stack traces of the goroutines running the subtasks with
+// the given keys.
+//
+// Task key is passed to Group.runTask only to make it visible in the stack
+// trace, this is where it's found.
+func taskStacks(keys map[int64]bool) map[int64]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	const marker = ".(*Group).runTask("
+	stacks := map[int64]string{}
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		for _, line := range strings.Split(stack, "\n") {
+			i := strings.Index(line, marker)
+			if i < 0 {
+				continue
+			}
+			for _, arg := range strings.Split(line[i+len(marker):], ", ") {
+				arg = strings.Trim(arg, "{}?)")
+				key, err := strconv.ParseUint(strings.TrimPrefix(arg, "0x"), 16, 64)
+				if err == nil && keys[int64(key)] {
+					stacks[int64(key)] = stack
+					break
+				}
+			}
+			break
+		}
+	}
+	return stacks
+}
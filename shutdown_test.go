@@ -0,0 +1,84 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func blockOn(ch <-chan struct{}) {
+	<-ch
+}
+
+func TestExitWithGrace(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+	g.Spawn("obedient", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.EqualError(t, g.ExitWithGrace(errors.New("oops"), time.Second), "oops")
+}
+
+func TestExitWithGraceTimeout(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+	release := make(chan struct{})
+	g.Spawn("obedient", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Spawn("stubborn", Fail, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	g.Spawn("hanging", Continue, func(ctx context.Context) error {
+		blockOn(release)
+		return nil
+	})
+
+	err := g.ExitWithGrace(nil, 10*time.Millisecond)
+	var timeoutErr ShutdownTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.EqualError(t, err, "shutdown timed out after 10ms, tasks still running: stubborn, hanging")
+	require.Len(t, timeoutErr.Tasks, 2)
+	require.Equal(t, "stubborn", timeoutErr.Tasks[0].Name)
+	require.Contains(t, timeoutErr.Tasks[0].Stack, "TestExitWithGraceTimeout")
+	require.NotContains(t, timeoutErr.Tasks[0].Stack, "blockOn")
+	require.Contains(t, timeoutErr.Tasks[1].Stack, "blockOn")
+
+	close(release)
+	require.NoError(t, g.Wait())
+}
+
+func TestWatchdog(t *testing.T) {
+	ctx := context.Background()
+
+	stuck := make(chan Event, 1)
+	g := NewGroup(ctx, WithWatchdog(10*time.Millisecond), WithObserver(func(event Event) {
+		if event.Type == EventTaskStuck {
+			stuck <- event
+		}
+	}))
+	release := make(chan struct{})
+	g.Spawn("obedient", Continue, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	g.Spawn("hanging", Continue, func(ctx context.Context) error {
+		blockOn(release)
+		return nil
+	})
+
+	g.Exit(nil)
+	event := <-stuck
+	close(release)
+	require.NoError(t, g.Wait())
+
+	require.Equal(t, "hanging", event.Task)
+	require.Contains(t, event.Stack, "parallel.blockOn(")
+	require.Empty(t, stuck)
+}
@@ -0,0 +1,55 @@
+package parallel
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RunWithSignals is like Run, but additionally shuts the group down gracefully
+// when the process receives one of the given signals. If no signals are
+// given, SIGINT and SIGTERM are handled.
+//
+// Shutdown caused by a signal is not an error by itself: if all the subtasks
+// return nil or context.Canceled, RunWithSignals returns nil.
+//
+// Example:
+//
+//	func main() {
+//	    err := parallel.RunWithSignals(ctx, func(ctx context.Context, spawn parallel.SpawnFn) error {
+//	        spawn("server", parallel.Fail, server.Run)
+//	        return nil
+//	    })
+//	    ...
+//	}
+func RunWithSignals(ctx context.Context, start func(ctx context.Context, spawn SpawnFn) error, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	g := NewGroup(ctx)
+
+	// The signal handler is not a subtask, so that it does not prevent the
+	// group from finishing when all the subtasks are done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			g.log.Debug("Signal received, shutting down", "signal", sig.String())
+			g.Exit(nil)
+		case <-stop:
+		}
+	}()
+
+	if err := start(g.Context(), g.Spawn); err != nil {
+		g.Exit(err)
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,39 @@
+package parallel
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWithSignals(t *testing.T) {
+	ctx := context.Background()
+	err := RunWithSignals(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("server", Fail, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		spawn("signal", Continue, func(ctx context.Context) error {
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				return err
+			}
+			return p.Signal(os.Interrupt)
+		})
+		return nil
+	}, os.Interrupt)
+	require.NoError(t, err)
+}
+
+func TestRunWithSignalsFinishes(t *testing.T) {
+	ctx := context.Background()
+	err := RunWithSignals(ctx, func(ctx context.Context, spawn SpawnFn) error {
+		spawn("job", Continue, func(ctx context.Context) error {
+			return nil
+		})
+		return nil
+	})
+	require.NoError(t, err)
+}
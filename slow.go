@@ -0,0 +1,60 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithSlowTaskThreshold makes the group log every run of a subtask taking
+// longer than d, and every subtask taking longer than d to return after its
+// context has been closed, with the time elapsed so far. It helps to find the
+// subtasks holding up the shutdown.
+func WithSlowTaskThreshold(d time.Duration) GroupOption {
+	return func(g *Group) {
+		g.slowTask = d
+	}
+}
+
+// watchSlow reports the run of the subtask started at the given time if it's
+// slow, see WithSlowTaskThreshold. The returned function must be called when
+// the run returns.
+func (g *Group) watchSlow(ctx context.Context, st *subtask, started time.Time) (stop func()) {
+	if g.slowTask <= 0 {
+		return func() {}
+	}
+
+	running := g.clock.AfterFunc(g.slowTask, func() {
+		g.log.Error("Task running slowly", "task", st.path, "elapsed", g.clock.Now().Sub(started))
+	})
+
+	var mu sync.Mutex
+	var exiting Timer
+	var finished bool
+	stopCanceled := context.AfterFunc(ctx, func() {
+		canceled := g.clock.Now()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if finished {
+			return
+		}
+		exiting = g.clock.AfterFunc(g.slowTask, func() {
+			g.log.Error("Task slow to exit", "task", st.path, "elapsed", g.clock.Now().Sub(canceled))
+		})
+	})
+
+	return func() {
+		running.Stop()
+		stopCanceled()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		finished = true
+		if exiting != nil {
+			exiting.Stop()
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowTaskThreshold(t *testing.T) {
+	log := newRecordingLogger()
+	g := NewGroup(context.Background(), WithLogger(log), WithSlowTaskThreshold(20*time.Millisecond))
+
+	g.Spawn("fast", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	g.Spawn("slow", Continue, func(ctx context.Context) error {
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	})
+	g.Spawn("straggler", Fail, func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(60 * time.Millisecond)
+		return ctx.Err()
+	})
+	time.Sleep(80 * time.Millisecond)
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	var slow []string
+	for _, entry := range *log.entries {
+		if entry == ": Task running slowly" || entry == ": Task slow to exit" {
+			slow = append(slow, entry)
+		}
+	}
+	require.Equal(t, []string{
+		": Task running slowly", // slow
+		": Task running slowly", // straggler
+		": Task slow to exit",   // straggler
+	}, slow)
+}
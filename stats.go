@@ -0,0 +1,89 @@
+package parallel
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Stats is a snapshot of the counters of a group, cheap enough to be taken
+// for periodic logging
+type Stats struct {
+	// Spawned is the number of subtasks spawned or attached so far
+	Spawned int
+
+	// Running is the number of running subtasks, including the queued ones
+	Running int
+
+	// Succeeded is the number of subtasks finished with nil or
+	// context.Canceled
+	Succeeded int
+
+	// Failed is the number of subtasks finished with another error, except
+	// for panics
+	Failed int
+
+	// Panicked is the number of subtasks finished with PanicError
+	Panicked int
+
+	// Oldest is the hierarchical name of the running subtask which has started
+	// its current run first, see TaskInfo.Path. It's empty if no subtask is
+	// running.
+	Oldest string
+
+	// OldestAge is the duration of the current run of the Oldest subtask
+	OldestAge time.Duration
+
+	// Uptime is the time elapsed since the group was created
+	Uptime time.Duration
+}
+
+// taskCounts holds the counters reported by Stats
+type taskCounts struct {
+	spawned   int
+	succeeded int
+	failed    int
+	panicked  int
+}
+
+// count counts the subtask finished with err. The mutex must be held.
+func (c *taskCounts) count(err error) {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		c.succeeded++
+	case errors.As(err, new(PanicError)):
+		c.panicked++
+	default:
+		c.failed++
+	}
+}
+
+// Stats returns the counters of the subtasks of the group. Unlike Tasks, it
+// doesn't include the subtasks of subgroups.
+func (g *Group) Stats() Stats {
+	now := g.clock.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := Stats{
+		Spawned:   g.counts.spawned,
+		Running:   g.running,
+		Succeeded: g.counts.succeeded,
+		Failed:    g.counts.failed,
+		Panicked:  g.counts.panicked,
+		Uptime:    now.Sub(g.created),
+	}
+	var oldest *subtask
+	for _, st := range g.tasks {
+		if st.state == TaskRunning && !st.started.IsZero() && (oldest == nil || st.started.Before(oldest.started)) {
+			oldest = st
+		}
+	}
+	if oldest != nil {
+		stats.Oldest = oldest.path
+		stats.OldestAge = now.Sub(oldest.started)
+	}
+	return stats
+}
@@ -0,0 +1,50 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStats(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	g.Spawn("succeeded", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	g.Spawn("failed", OnError, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	g.Spawn("panicked", OnError, func(ctx context.Context) error {
+		panic("oops")
+	})
+	started := make(chan struct{})
+	g.Spawn("running", Continue, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	_, err := g.WaitN(ctx, 3)
+	require.NoError(t, err)
+
+	stats := g.Stats()
+	require.Equal(t, 4, stats.Spawned)
+	require.Equal(t, 1, stats.Running)
+	require.Equal(t, 1, stats.Succeeded)
+	require.Equal(t, 1, stats.Failed)
+	require.Equal(t, 1, stats.Panicked)
+	require.Equal(t, "running", stats.Oldest)
+	require.Positive(t, stats.Uptime)
+	require.GreaterOrEqual(t, stats.Uptime, stats.OldestAge)
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+	stats = g.Stats()
+	require.Equal(t, 0, stats.Running)
+	require.Equal(t, 2, stats.Succeeded)
+	require.Empty(t, stats.Oldest)
+}
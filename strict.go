@@ -0,0 +1,36 @@
+package parallel
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// WithStrict makes the group detect misuse which otherwise goes unnoticed:
+//
+//   - Once Wait has returned, the group is closed like by Close, so spawning
+//     more subtasks into the finished group fails with ErrGroupClosed instead
+//     of reusing it.
+//   - WaitCtx and Complete panic if called with the context of a subtask of the
+//     group or of one of its subgroups, as they would wait for the caller
+//     itself and never return. Wait takes no context, so it can't tell.
+func WithStrict() GroupOption {
+	return func(g *Group) {
+		g.strict = true
+	}
+}
+
+// checkWaiter panics in strict mode if ctx belongs to a subtask which has to
+// finish before the group does
+func (g *Group) checkWaiter(ctx context.Context) {
+	if !g.strict {
+		return
+	}
+	st, _ := ctx.Value(subtaskKey).(*subtask)
+	for st != nil {
+		if st.group == g {
+			panic(errors.Errorf("task %s waits for its own group, which can never finish", st.path))
+		}
+		st = st.group.owner
+	}
+}
@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictSpawnAfterWait(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithStrict())
+
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+
+	err := g.SpawnE("late", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.ErrorIs(t, err, ErrGroupClosed)
+}
+
+func TestStrictSelfWait(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithStrict())
+
+	var recovered interface{}
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		return Run(ctx, func(ctx context.Context, spawn SpawnFn) error {
+			spawn("nested", Continue, func(ctx context.Context) error {
+				defer func() {
+					recovered = recover()
+				}()
+				return g.WaitCtx(ctx)
+			})
+			return nil
+		})
+	})
+	require.NoError(t, g.Wait())
+	require.NotNil(t, recovered)
+	require.Contains(t, recovered.(error).Error(), "task task waits for its own group")
+
+	// Waiting from the subtask of another group is fine
+	other := NewGroup(ctx, WithStrict())
+	other.Spawn("waiter", Continue, func(ctx context.Context) error {
+		return g.WaitCtx(ctx)
+	})
+	require.NoError(t, other.Wait())
+}
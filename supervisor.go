@@ -0,0 +1,256 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Strategy is an enumeration of restart strategies used by Supervisor. It
+// specifies which children are restarted when one of them fails.
+type Strategy int
+
+const (
+	// OneForOne means only the failed child is restarted
+	OneForOne Strategy = iota
+
+	// OneForAll means all the children are stopped and then restarted when any
+	// of them fails
+	OneForAll
+
+	// RestForOne means the failed child and all the children added after it
+	// are stopped and then restarted
+	RestForOne
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case OneForOne:
+		return "OneForOne"
+	case OneForAll:
+		return "OneForAll"
+	case RestForOne:
+		return "RestForOne"
+	default:
+		return fmt.Sprintf("invalid Strategy: %d", s)
+	}
+}
+
+// Supervisor runs a set of child tasks and restarts them when they fail,
+// similarly to Erlang supervision trees.
+//
+// A child fails when it returns an error or panics. A child returning nil is
+// considered finished and is not restarted. If children fail more than
+// maxRestarts times within the window, the supervisor gives up and returns the
+// error of the last failure.
+//
+// Supervisor is a task itself, so it can be spawned into a group or nested in
+// another supervisor:
+//
+//	sup := parallel.NewSupervisor(parallel.OneForOne, 5, time.Minute)
+//	sup.Add("fetcher", fetcher.Run)
+//	sup.Add("indexer", indexer.Run)
+//	spawn("supervisor", parallel.Fail, sup.Run)
+type Supervisor struct {
+	strategy    Strategy
+	maxRestarts int
+	window      time.Duration
+	children    []supervisedChild
+}
+
+type supervisedChild struct {
+	name string
+	task Task
+}
+
+// NewSupervisor creates a new Supervisor using the given restart strategy and
+// restart limit
+func NewSupervisor(strategy Strategy, maxRestarts int, window time.Duration) *Supervisor {
+	return &Supervisor{
+		strategy:    strategy,
+		maxRestarts: maxRestarts,
+		window:      window,
+	}
+}
+
+// Add adds a child task to the supervisor. Children are started in the order
+// they are added. Add must not be called after Run.
+func (s *Supervisor) Add(name string, task Task) {
+	s.children = append(s.children, supervisedChild{name: name, task: task})
+}
+
+// Run runs the children until ctx is closed, all the children finish, or the
+// restart limit is exceeded
+func (s *Supervisor) Run(ctx context.Context) error {
+	r := &supervisorRun{
+		Supervisor: s,
+		group:      NewGroup(ctx),
+		exits:      make(chan childExit, len(s.children)),
+		instances:  make([]*childInstance, len(s.children)),
+	}
+	err := r.run(ctx)
+	r.group.Exit(nil)
+	if err2 := r.group.Wait(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// childExit is reported by a child instance when it finishes
+type childExit struct {
+	instance *childInstance
+	err      error
+}
+
+// childInstance is a single run of a child
+type childInstance struct {
+	index int
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped bool
+}
+
+func (ci *childInstance) stop() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.stopped = true
+	if ci.cancel != nil {
+		ci.cancel()
+	}
+}
+
+type supervisorRun struct {
+	*Supervisor
+
+	group     *Group
+	exits     chan childExit
+	instances []*childInstance
+	pending   []childExit
+	restarts  []time.Time
+}
+
+func (r *supervisorRun) run(ctx context.Context) error {
+	for i := range r.children {
+		r.start(i)
+	}
+
+	for {
+		if r.runningCount() == 0 {
+			return nil
+		}
+
+		var exit childExit
+		if len(r.pending) > 0 {
+			exit = r.pending[0]
+			r.pending = r.pending[1:]
+		} else {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case exit = <-r.exits:
+			}
+		}
+
+		i := exit.instance.index
+		if r.instances[i] != exit.instance {
+			continue
+		}
+		r.instances[i] = nil
+		if exit.err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		name := r.children[i].name
+		if !r.allowRestart() {
+			return errors.Wrapf(exit.err, "task %s exceeded restart limit", name)
+		}
+		loggerFromContext(ctx).Error("Task failed, restarting", "task", name, "strategy", r.strategy, "error", exit.err)
+
+		switch r.strategy {
+		case OneForAll:
+			r.stopFrom(0)
+			for j := range r.children {
+				r.start(j)
+			}
+		case RestForOne:
+			r.stopFrom(i + 1)
+			for j := i; j < len(r.children); j++ {
+				r.start(j)
+			}
+		default:
+			r.start(i)
+		}
+	}
+}
+
+func (r *supervisorRun) runningCount() int {
+	var n int
+	for _, ci := range r.instances {
+		if ci != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// allowRestart records a restart and reports whether it fits into the limit
+func (r *supervisorRun) allowRestart() bool {
+	now := r.group.clock.Now()
+	var recent []time.Time
+	for _, t := range r.restarts {
+		if now.Sub(t) < r.window {
+			recent = append(recent, t)
+		}
+	}
+	r.restarts = append(recent, now)
+	return len(r.restarts) <= r.maxRestarts
+}
+
+// stopFrom stops running children starting from index from, in reverse order,
+// and waits for them to exit. Exits of other children are kept for later
+// processing.
+func (r *supervisorRun) stopFrom(from int) {
+	for j := len(r.instances) - 1; j >= from; j-- {
+		ci := r.instances[j]
+		if ci == nil {
+			continue
+		}
+		ci.stop()
+		for {
+			exit := <-r.exits
+			if exit.instance == ci {
+				break
+			}
+			r.pending = append(r.pending, exit)
+		}
+		r.instances[j] = nil
+	}
+}
+
+func (r *supervisorRun) start(i int) {
+	ci := &childInstance{index: i}
+	r.instances[i] = ci
+	child := r.children[i]
+	r.group.Spawn(child.name, Continue, func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		ci.mu.Lock()
+		ci.cancel = cancel
+		if ci.stopped {
+			cancel()
+		}
+		ci.mu.Unlock()
+
+		r.exits <- childExit{instance: ci, err: runTask(ctx, taskPath(ctx, child.name), child.task)}
+		return nil
+	})
+}
@@ -0,0 +1,118 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorOneForOne(t *testing.T) {
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var flakyRuns, stableRuns int
+	stableStarted := make(chan struct{}, 10)
+	sup := NewSupervisor(OneForOne, 5, time.Minute)
+	sup.Add("stable", func(ctx context.Context) error {
+		stableRuns++
+		stableStarted <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	sup.Add("flaky", func(ctx context.Context) error {
+		flakyRuns++
+		if flakyRuns < 3 {
+			return errors.New("oops")
+		}
+		cancel()
+		return nil
+	})
+
+	require.ErrorIs(t, sup.Run(ctx), context.Canceled)
+	require.Equal(t, 3, flakyRuns)
+	require.Equal(t, 1, stableRuns)
+}
+
+func TestSupervisorOneForAll(t *testing.T) {
+	ctx := context.Background()
+
+	var firstRuns, secondRuns int
+	second := make(chan struct{})
+	sup := NewSupervisor(OneForAll, 5, time.Minute)
+	sup.Add("first", func(ctx context.Context) error {
+		firstRuns++
+		<-second
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	sup.Add("second", func(ctx context.Context) error {
+		secondRuns++
+		second <- struct{}{}
+		if secondRuns < 2 {
+			return errors.New("oops")
+		}
+		return nil
+	})
+
+	// After the second child finishes successfully, the first one keeps
+	// running, so the supervisor is stopped by the context
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, sup.Run(ctx), context.DeadlineExceeded)
+	require.Equal(t, 2, firstRuns)
+	require.Equal(t, 2, secondRuns)
+}
+
+func TestSupervisorRestForOne(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	started := make(chan struct{}, 3)
+	sup := NewSupervisor(RestForOne, 5, time.Minute)
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		sup.Add(name, func(ctx context.Context) error {
+			mu.Lock()
+			runs[name]++
+			n := runs[name]
+			mu.Unlock()
+
+			switch {
+			case name == "second" && n == 1:
+				// Wait for the other children to start before failing
+				<-started
+				<-started
+				return errors.New("oops")
+			case name == "third" && n == 2:
+				return nil
+			}
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, sup.Run(ctx), context.DeadlineExceeded)
+	require.Equal(t, map[string]int{"first": 1, "second": 2, "third": 2}, runs)
+}
+
+func TestSupervisorRestartLimit(t *testing.T) {
+	ctx := context.Background()
+
+	var runs int
+	sup := NewSupervisor(OneForOne, 2, time.Minute)
+	sup.Add("broken", func(ctx context.Context) error {
+		runs++
+		return errors.New("oops")
+	})
+	require.EqualError(t, sup.Run(ctx), "task broken exceeded restart limit: oops")
+	require.Equal(t, 3, runs)
+}
@@ -0,0 +1,316 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const defaultTaskHistory = 100
+
+// TaskState is an enumeration of subtask states reported by Group.Tasks
+type TaskState int
+
+const (
+	// TaskQueued means the subtask waits for a free slot in a group created
+	// with WithMaxConcurrent, or for the group to be resumed, see Group.Pause
+	TaskQueued TaskState = iota
+
+	// TaskRunning means the subtask is running
+	TaskRunning
+
+	// TaskFinished means the subtask has finished
+	TaskFinished
+
+	// TaskRestarting means the subtask in Restart mode waits to be started
+	// again, see WithRestartBackoff
+	TaskRestarting
+
+	// TaskCoolingDown means restarts of the subtask are suspended because it
+	// has failed too often, see WithCircuitBreaker
+	TaskCoolingDown
+)
+
+func (s TaskState) String() string {
+	switch s {
+	case TaskQueued:
+		return "queued"
+	case TaskRunning:
+		return "running"
+	case TaskFinished:
+		return "finished"
+	case TaskRestarting:
+		return "restarting"
+	case TaskCoolingDown:
+		return "cooling down"
+	default:
+		return fmt.Sprintf("invalid TaskState: %d", s)
+	}
+}
+
+// TaskInfo describes a subtask spawned in a group
+type TaskInfo struct {
+	// Name is the name passed to Spawn
+	Name string
+
+	// Path is the hierarchical name of the subtask, prefixed by the names of
+	// the subtasks owning the parent groups, e.g. updater.fetcher
+	Path string
+
+	// ID is the unique ID of the subtask, the same one as logged when the
+	// subtask is spawned
+	ID int64
+
+	// Labels are the labels of the subtask, see WithLabels
+	Labels map[string]string
+
+	// OnExit is the exit handling mode passed to Spawn, or the one chosen by
+	// OnExitFunc
+	OnExit OnExit
+
+	// State is the current state of the subtask
+	State TaskState
+
+	// Started is the time the subtask started its last run, zero if it's
+	// still queued
+	Started time.Time
+
+	// Duration is how long the last finished run of the subtask took, zero if
+	// no run has finished yet
+	Duration time.Duration
+
+	// Err is the error returned by the subtask if it has finished
+	Err error
+
+	// Restarts is the number of times the subtask has been restarted
+	Restarts int
+
+	// LastErr is the last error returned by any run of the subtask
+	LastErr error
+
+	// Subgroups lists the subtasks of the groups created by the subtask, one
+	// entry per group
+	Subgroups [][]TaskInfo
+}
+
+// Tasks returns information about all the subtasks spawned in the group so far,
+// in the order of spawning.
+//
+// Only the most recently finished subtasks are reported, see WithTaskHistory.
+//
+// Subtasks of subgroups, created by NewSubgroup or by calling NewGroup with the
+// context of a subtask, are reported recursively.
+func (g *Group) Tasks() []TaskInfo {
+	g.mu.Lock()
+	tasks := g.sortedTasks(true)
+	infos := make([]TaskInfo, 0, len(tasks))
+	subgroups := make([][]*Group, 0, len(tasks))
+	for _, st := range tasks {
+		infos = append(infos, st.info())
+		subgroups = append(subgroups, append([]*Group(nil), st.subgroups...))
+	}
+	g.mu.Unlock()
+
+	// Subgroups are queried without holding the lock of the parent group
+	for i, groups := range subgroups {
+		for _, sub := range groups {
+			infos[i].Subgroups = append(infos[i].Subgroups, sub.Tasks())
+		}
+	}
+	return infos
+}
+
+// TaskError is the error of a failed subtask, set as the group result if the
+// group was created with WithTaskErrors. Its message is the one of Err.
+type TaskError struct {
+	// Name is the hierarchical name of the subtask, see TaskInfo.Path
+	Name string
+
+	// ID is the unique ID of the subtask
+	ID int64
+
+	// OnExit is the exit handling mode of the subtask
+	OnExit OnExit
+
+	// Duration is how long the last run of the subtask took
+	Duration time.Duration
+
+	// Err is the error returned by the subtask
+	Err error
+}
+
+func (err TaskError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the error returned by the subtask
+func (err TaskError) Unwrap() error {
+	return err.Err
+}
+
+// WithTaskErrors makes the group wrap errors of failed subtasks into
+// TaskError, so the caller of Wait may find out which subtask has failed using
+// errors.As
+func WithTaskErrors() GroupOption {
+	return func(g *Group) {
+		g.taskErrors = true
+	}
+}
+
+// taskError wraps err returned by the subtask into TaskError if configured by
+// WithTaskErrors. The mutex of the group must be held.
+func (g *Group) taskError(st *subtask, err error) error {
+	if !g.taskErrors {
+		return err
+	}
+	return TaskError{
+		Name:     st.path,
+		ID:       st.id,
+		OnExit:   st.onExit,
+		Duration: st.duration,
+		Err:      err,
+	}
+}
+
+// Errors returns the results of the finished subtasks keyed by name, nil for
+// the ones which succeeded. Subtasks which haven't finished are not included.
+// If many subtasks have the same name, the result of the last spawned one is
+// reported.
+//
+// Like Tasks, it only covers the most recently finished subtasks, see
+// WithTaskHistory.
+func (g *Group) Errors() map[string]error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	errs := map[string]error{}
+	for _, st := range g.sortedTasks(true) {
+		if st.state == TaskFinished {
+			errs[st.name] = st.err
+		}
+	}
+	return errs
+}
+
+// info describes the subtask, without subgroups. The mutex of the group must
+// be held.
+func (st *subtask) info() TaskInfo {
+	return TaskInfo{
+		Name:     st.name,
+		Path:     st.path,
+		ID:       st.id,
+		Labels:   st.labels,
+		OnExit:   st.onExit,
+		State:    st.state,
+		Started:  st.started,
+		Duration: st.duration,
+		Err:      st.err,
+		Restarts: st.restarts,
+		LastErr:  st.lastErr,
+	}
+}
+
+// WithTaskHistory sets the number of the most recently finished subtasks
+// reported by Tasks, 100 by default. Zero disables reporting of finished
+// subtasks, a negative n retains all of them, which makes the memory used by
+// the group grow with every subtask spawned.
+func WithTaskHistory(n int) GroupOption {
+	return func(g *Group) {
+		g.history = n
+	}
+}
+
+// retire moves the finished subtask to the history, dropping the oldest one if
+// the history is full. Must be called with the mutex of the group held.
+func (g *Group) retire(st *subtask) {
+	delete(g.tasks, st.id)
+	g.retired++
+	// The task may hold resources which are not needed anymore
+	st.task = nil
+
+	if g.history == 0 {
+		return
+	}
+	if g.history > 0 && len(g.finished) == g.history {
+		copy(g.finished, g.finished[1:])
+		g.finished = g.finished[:len(g.finished)-1]
+	}
+	g.finished = append(g.finished, st)
+}
+
+// sortedTasks returns the unfinished subtasks, optionally with the retained
+// finished ones, in the order of spawning. Must be called with the mutex of the
+// group held.
+func (g *Group) sortedTasks(withFinished bool) []*subtask {
+	tasks := make([]*subtask, 0, len(g.tasks)+len(g.finished))
+	for _, st := range g.tasks {
+		tasks = append(tasks, st)
+	}
+	if withFinished {
+		tasks = append(tasks, g.finished...)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].id < tasks[j].id
+	})
+	return tasks
+}
+
+// TaskName returns the hierarchical name of the subtask owning ctx, the same
+// one as TaskInfo.Path, or an empty string if ctx doesn't belong to a subtask
+func TaskName(ctx context.Context) string {
+	if st, ok := ctx.Value(subtaskKey).(*subtask); ok {
+		return st.path
+	}
+	return ""
+}
+
+// taskPath returns the hierarchical name of the subtask owning ctx, falling
+// back to the given name if ctx doesn't belong to a subtask
+func taskPath(ctx context.Context, name string) string {
+	if path := TaskName(ctx); path != "" {
+		return path
+	}
+	return name
+}
+
+// WithDeterministicIDs makes the group number its subtasks 1, 2, 3 and so on
+// in the order of spawning, instead of using IDs unique in the process, so the
+// IDs logged and reported by Tasks are the same on every run, e.g. in tests.
+// The IDs are unique only within the group then.
+func WithDeterministicIDs() GroupOption {
+	return func(g *Group) {
+		g.sequentialIDs = true
+	}
+}
+
+// newTaskID returns the key identifying a new subtask in the process, and its
+// ID, which is the same as the key unless the group was created with
+// WithDeterministicIDs
+func (g *Group) newTaskID() (key, id int64) {
+	key = atomic.AddInt64(&nextTaskID, 1)
+	if g.sequentialIDs {
+		return key, g.lastID.Add(1)
+	}
+	return key, key
+}
+
+// TaskID returns the ID of the subtask owning ctx, or 0 if ctx doesn't belong
+// to a subtask
+func TaskID(ctx context.Context) int64 {
+	if st, ok := ctx.Value(subtaskKey).(*subtask); ok {
+		return st.id
+	}
+	return 0
+}
+
+// GroupName returns the hierarchical name of the group the subtask owning ctx
+// was spawned in, which is the name of the subtask owning the group, or an
+// empty string for top-level groups
+func GroupName(ctx context.Context) string {
+	if st, ok := ctx.Value(subtaskKey).(*subtask); ok {
+		return st.group.path
+	}
+	return ""
+}
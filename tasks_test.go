@@ -0,0 +1,272 @@
+package parallel
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupTasks(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(2))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	g.Spawn("failed", Continue, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	<-g.Context().Done()
+	g.Spawn("running", Continue, func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	g.Spawn("waiting", Continue, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	g.Spawn("queued", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	<-started
+
+	tasks := g.Tasks()
+	require.Len(t, tasks, 4)
+	require.Equal(t, "failed", tasks[0].Name)
+	require.Equal(t, Continue, tasks[0].OnExit)
+	require.Equal(t, TaskFinished, tasks[0].State)
+	require.EqualError(t, tasks[0].Err, "oops")
+	require.False(t, tasks[0].Started.IsZero())
+
+	require.Equal(t, "running", tasks[1].Name)
+	require.Equal(t, TaskRunning, tasks[1].State)
+	require.False(t, tasks[1].Started.IsZero())
+	require.Zero(t, tasks[1].Duration)
+	require.NoError(t, tasks[1].Err)
+
+	require.Equal(t, TaskQueued, tasks[3].State)
+	require.True(t, tasks[3].Started.IsZero())
+	require.NotEqual(t, tasks[0].ID, tasks[1].ID)
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	require.EqualError(t, g.Wait(), "oops")
+	tasks = g.Tasks()
+	for _, info := range tasks {
+		require.Equal(t, TaskFinished, info.State)
+	}
+	require.GreaterOrEqual(t, tasks[1].Duration, 10*time.Millisecond)
+}
+
+func TestGroupTasksPath(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	updater := NewSubgroup(g.Spawn, "updater", Continue)
+	fetcher := NewSubgroup(updater.Spawn, "fetcher", Continue)
+	fetcher.Spawn("worker", Fail, func(ctx context.Context) error {
+		return nil
+	})
+	require.EqualError(t, g.Wait(), "task updater.fetcher.worker terminated unexpectedly")
+
+	tasks := g.Tasks()
+	require.Equal(t, "updater", tasks[0].Path)
+	tasks = tasks[0].Subgroups[0]
+	require.Equal(t, "fetcher", tasks[0].Name)
+	require.Equal(t, "updater.fetcher", tasks[0].Path)
+	tasks = tasks[0].Subgroups[0]
+	require.Equal(t, "worker", tasks[0].Name)
+	require.Equal(t, "updater.fetcher.worker", tasks[0].Path)
+}
+
+func TestGroupTasksFinishedSubgroups(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx)
+
+	looped := make(chan error, 1)
+	g.Spawn("loop", Exit, func(ctx context.Context) error {
+		for i := 0; i < 100; i++ {
+			if err := ForEach(ctx, []int{1, 2}, 0, func(ctx context.Context, item int) error {
+				return nil
+			}); err != nil {
+				looped <- err
+				return err
+			}
+		}
+		looped <- nil
+		<-ctx.Done()
+		return nil
+	})
+	require.NoError(t, <-looped)
+	require.Empty(t, g.Tasks()[0].Subgroups)
+
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+}
+
+func TestTaskIdentity(t *testing.T) {
+	ctx := context.Background()
+	require.Empty(t, TaskName(ctx))
+	require.Zero(t, TaskID(ctx))
+	require.Empty(t, GroupName(ctx))
+
+	type identity struct {
+		name  string
+		id    int64
+		group string
+	}
+
+	g := NewGroup(ctx)
+	sub := NewSubgroup(g.Spawn, "sub", Continue)
+	ch := make(chan identity, 1)
+	sub.Spawn("task", Continue, func(ctx context.Context) error {
+		ch <- identity{name: TaskName(ctx), id: TaskID(ctx), group: GroupName(ctx)}
+		return nil
+	})
+	id := <-ch
+	sub.Exit(nil)
+	g.Exit(nil)
+	require.NoError(t, g.Wait())
+
+	require.Equal(t, "sub.task", id.name)
+	require.Equal(t, "sub", id.group)
+	require.Equal(t, sub.Tasks()[0].ID, id.id)
+}
+
+func TestGroupDeterministicIDs(t *testing.T) {
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	blocking := []Task{
+		func(ctx context.Context) error {
+			started <- struct{}{}
+			blockOn(release)
+			return nil
+		},
+		func(ctx context.Context) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		},
+	}
+	var groups []*Group
+	for _, task := range blocking {
+		g := NewGroup(ctx, WithDeterministicIDs())
+		g.Spawn("first", Continue, func(ctx context.Context) error {
+			return nil
+		})
+		g.Spawn("second", Continue, task)
+		groups = append(groups, g)
+	}
+
+	for _, g := range groups {
+		_, err := g.WaitAny(ctx)
+		require.NoError(t, err)
+		tasks := g.Tasks()
+		require.Len(t, tasks, 2)
+		require.EqualValues(t, 1, tasks[0].ID)
+		require.EqualValues(t, 2, tasks[1].ID)
+	}
+
+	<-started
+	<-started
+
+	// The stacks of the subtasks of the same ID are told apart
+	require.Eventually(t, func() bool {
+		unfinished := groups[0].Unfinished()
+		return len(unfinished) == 1 && unfinished[0].ID == 2 && strings.Contains(unfinished[0].Stack, "blockOn")
+	}, time.Second, time.Millisecond)
+	unfinished := groups[1].Unfinished()
+	require.Len(t, unfinished, 1)
+	require.NotContains(t, unfinished[0].Stack, "blockOn")
+	require.Contains(t, unfinished[0].Stack, "TestGroupDeterministicIDs")
+
+	close(release)
+	for _, g := range groups {
+		require.NoError(t, g.Wait())
+	}
+}
+
+func TestGroupTaskHistory(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithTaskHistory(2))
+
+	for _, name := range []string{"first", "second", "third"} {
+		g.Spawn(name, Continue, func(ctx context.Context) error {
+			return nil
+		})
+		require.NoError(t, g.Wait())
+	}
+
+	tasks := g.Tasks()
+	require.Len(t, tasks, 2)
+	require.Equal(t, "second", tasks[0].Name)
+	require.Equal(t, "third", tasks[1].Name)
+
+	g = NewGroup(ctx, WithTaskHistory(0))
+	g.Spawn("task", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, g.Wait())
+	require.Empty(t, g.Tasks())
+}
+
+func TestGroupTasksPathIgnoresProfilerLabels(t *testing.T) {
+	ctx := context.Background()
+	pprof.Do(ctx, pprof.Labels("task", "http-handler"), func(ctx context.Context) {
+		g := NewGroup(ctx)
+		g.Spawn("worker", Continue, func(ctx context.Context) error {
+			return nil
+		})
+		require.NoError(t, g.Wait())
+		require.Equal(t, "worker", g.Tasks()[0].Path)
+	})
+}
+
+func TestGroupErrors(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithMaxConcurrent(1), WithMaxErrors(1))
+
+	release := make(chan struct{})
+	g.Spawn("ok", Continue, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	g.Spawn("failed", Continue, func(ctx context.Context) error {
+		return errors.New("oops")
+	})
+	require.Empty(t, g.Errors())
+
+	close(release)
+	require.NoError(t, g.Wait())
+	errs := g.Errors()
+	require.Len(t, errs, 2)
+	require.NoError(t, errs["ok"])
+	require.EqualError(t, errs["failed"], "oops")
+}
+
+func TestGroupTaskErrors(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithTaskErrors())
+
+	g.Spawn("failed", Fail, func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("oops")
+	})
+
+	err := g.Wait()
+	require.EqualError(t, err, "oops")
+
+	var taskErr TaskError
+	require.True(t, errors.As(err, &taskErr))
+	require.Equal(t, "failed", taskErr.Name)
+	require.Equal(t, Fail, taskErr.OnExit)
+	require.NotZero(t, taskErr.ID)
+	require.GreaterOrEqual(t, taskErr.Duration, 10*time.Millisecond)
+}
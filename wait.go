@@ -0,0 +1,46 @@
+package parallel
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// WaitAny blocks until any subtask of the group finishes, and returns the
+// description of the first subtask that has finished, see WaitN
+func (g *Group) WaitAny(ctx context.Context) (TaskInfo, error) {
+	return g.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n subtasks of the group have finished, counting from the
+// creation of the group, and returns the description of the n-th of them,
+// with its error in TaskInfo.Err. It doesn't wait for the other subtasks.
+//
+// If ctx closes first, ctx.Err() is returned. If the n-th subtask has already
+// dropped out of the history of finished subtasks (see WithTaskHistory), an
+// error is returned too.
+func (g *Group) WaitN(ctx context.Context, n int) (TaskInfo, error) {
+	for {
+		g.mu.Lock()
+		if g.retired >= n {
+			defer g.mu.Unlock()
+
+			i := len(g.finished) - (g.retired - n) - 1
+			if i < 0 {
+				return TaskInfo{}, errors.Errorf("subtask finished as number %d is not in the history anymore", n)
+			}
+			return g.finished[i].info(), nil
+		}
+		if g.settled == nil {
+			g.settled = make(chan struct{})
+		}
+		settled := g.settled
+		g.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return TaskInfo{}, ctx.Err()
+		case <-settled:
+		}
+	}
+}
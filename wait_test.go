@@ -0,0 +1,44 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitN(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(ctx, WithTaskHistory(1))
+
+	release := make(chan struct{})
+	g.Spawn("leader", Continue, func(ctx context.Context) error {
+		return nil
+	})
+	g.Spawn("worker", Continue, func(ctx context.Context) error {
+		<-release
+		return errors.New("oops")
+	})
+
+	info, err := g.WaitAny(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "leader", info.Name)
+	require.NoError(t, info.Err)
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = g.WaitN(waitCtx, 2)
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	info, err = g.WaitN(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, "worker", info.Name)
+	require.EqualError(t, info.Err, "oops")
+
+	_, err = g.WaitAny(ctx)
+	require.EqualError(t, err, "subtask finished as number 1 is not in the history anymore")
+
+	require.EqualError(t, g.Wait(), "oops")
+}
@@ -0,0 +1,66 @@
+// Package zaplogger adapts zap loggers, including the ones managed by
+// github.com/outofforest/logger, to parallel.Logger.
+package zaplogger
+
+import (
+	"context"
+
+	"github.com/outofforest/logger"
+	"go.uber.org/zap"
+
+	"github.com/outofforest/parallel"
+)
+
+// New returns a parallel.Logger writing to the given zap logger.
+//
+// Tasks of a group using this logger may retrieve the zap logger, named after
+// the task, using logger.Get from github.com/outofforest/logger.
+func New(l *zap.Logger) parallel.Logger {
+	return zapLogger{
+		base:  l,
+		sugar: l.WithOptions(zap.AddCallerSkip(1)).Sugar(),
+	}
+}
+
+// FromContext returns a parallel.Logger writing to the zap logger stored in ctx
+// by github.com/outofforest/logger. Use it to keep the logger of the caller:
+//
+//	parallel.Run(ctx, start, parallel.WithLogger(zaplogger.FromContext(ctx)))
+func FromContext(ctx context.Context) parallel.Logger {
+	return New(logger.Get(ctx))
+}
+
+type zapLogger struct {
+	base  *zap.Logger
+	sugar *zap.SugaredLogger
+}
+
+func (z zapLogger) Named(name string) parallel.Logger {
+	return New(z.base.Named(name))
+}
+
+func (z zapLogger) With(args ...interface{}) parallel.Logger {
+	sugar := z.sugar.With(args...)
+	return zapLogger{
+		base:  sugar.Desugar().WithOptions(zap.AddCallerSkip(-1)),
+		sugar: sugar,
+	}
+}
+
+func (z zapLogger) Debug(msg string, args ...interface{}) {
+	z.sugar.Debugw(msg, args...)
+}
+
+func (z zapLogger) Error(msg string, args ...interface{}) {
+	z.sugar.Errorw(msg, args...)
+}
+
+// DebugEnabled lets groups skip building debug entries discarded by zap
+func (z zapLogger) DebugEnabled() bool {
+	return z.base.Core().Enabled(zap.DebugLevel)
+}
+
+// Inject stores the zap logger in ctx for logger.Get
+func (z zapLogger) Inject(ctx context.Context) context.Context {
+	return logger.WithLogger(ctx, z.base)
+}
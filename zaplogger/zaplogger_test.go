@@ -0,0 +1,26 @@
+package zaplogger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/outofforest/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/outofforest/parallel"
+)
+
+func TestLoggerInContext(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.New(logger.DefaultConfig))
+	g := parallel.NewGroup(ctx, parallel.WithLogger(FromContext(ctx)))
+
+	loggers := make(chan *zap.Logger, 1)
+	sub := parallel.NewSubgroup(g.Spawn, "sub", parallel.Continue, zap.String("shard", "a"))
+	sub.Spawn("task", parallel.Exit, func(ctx context.Context) error {
+		loggers <- logger.Get(ctx)
+		return nil
+	})
+	require.NoError(t, g.Wait())
+	require.NotNil(t, <-loggers)
+}